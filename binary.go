@@ -49,10 +49,53 @@ var (
 
 // Expected address length
 var (
-	BinaryPortLen = 2
+	BinaryPortLen     = 2
+	BinaryUnixAddrLen = 108
 )
 
 // TLV types
 const (
-	TLVTypeNoop byte = 0x04
+	TLVTypeALPN      byte = 0x01
+	TLVTypeAuthority byte = 0x02
+	TLVTypeCRC32C    byte = 0x03
+	TLVTypeNoop      byte = 0x04
+	TLVTypeUniqueID  byte = 0x05
+	TLVTypeSSL       byte = 0x20
+	TLVTypeNetNS     byte = 0x30
+)
+
+// Cloud vendor TLV types. Placed in custom range 0xE0-0xEF, so not decoded
+// until requested.
+const (
+	TLVTypeGCP   byte = 0xE0
+	TLVTypeAWS   byte = 0xEA
+	TLVTypeAzure byte = 0xEE
+)
+
+// Cloud vendor sub-TLV types
+const (
+	TLVSubtypeAWSVPCEndpointID byte = 0x01
+	TLVSubtypeAzureLinkID      byte = 0x01
+)
+
+// PP2_TYPE_SSL sub-TLV types
+const (
+	TLVSubtypeSSLVersion byte = 0x21
+	TLVSubtypeSSLCN      byte = 0x22
+	TLVSubtypeSSLCipher  byte = 0x23
+	TLVSubtypeSSLSigAlg  byte = 0x24
+	TLVSubtypeSSLKeyAlg  byte = 0x25
+)
+
+// PP2_TYPE_SSL client flags
+const (
+	TLVClientSSL      byte = 0x01
+	TLVClientCertConn byte = 0x02
+	TLVClientCertSess byte = 0x04
+)
+
+// TLV value limits
+const (
+	TLVUniqueIDMaxLen = 128
+	TLVCRC32CLen      = 4
 )
@@ -5,6 +5,7 @@ import (
 	"bytes"
 	"encoding/binary"
 	"errors"
+	"io"
 	"net"
 )
 
@@ -25,7 +26,8 @@ const (
 
 // BinaryHeaderParser parse proxyprotocol header from Reader
 type BinaryHeaderParser struct {
-	logger Logger
+	logger      Logger
+	tlvDecoders *TLVDecoderRegistry
 }
 
 // NewBinaryHeaderParser construct BinaryHeaderParser
@@ -35,33 +37,83 @@ func NewBinaryHeaderParser(logger Logger) BinaryHeaderParser {
 	}
 }
 
-// Parse buffer
+// WithTLVDecoderRegistry copy BinaryHeaderParser and set TLVDecoderRegistry.
+// Values of TLVs with registered types decoded into Header.Decoded.
+func (parser BinaryHeaderParser) WithTLVDecoderRegistry(tlvDecoders *TLVDecoderRegistry) BinaryHeaderParser {
+	newParser := parser
+	newParser.tlvDecoders = tlvDecoders
+	return newParser
+}
+
+// Parse buffer.
+//
+// Protocol block of LOCAL command parsed when possible, but its errors ignored.
+//
+// If header contain PP2_TYPE_CRC32C TLV and checksum not match, then parsed
+// header returned together with ErrChecksumMismatch.
 func (parser BinaryHeaderParser) Parse(buf *bufio.Reader) (*Header, error) {
+	metaBuf, addressesBuf, err := parser.readHeader(buf)
+	if err != nil {
+		return nil, err
+	}
+
+	var command Command
+	switch metaBuf[versionCommandPos] & BinaryCommandMask {
+	case BinaryCommandProxy:
+		command = CommandProxy
+	case BinaryCommandLocal:
+		command = CommandLocal
+	default:
+		return nil, ErrUnknownCommand
+	}
+
+	header, err := parser.parseProtocolBlock(metaBuf, addressesBuf)
+	if err != nil && err != ErrChecksumMismatch {
+		if command == CommandProxy {
+			return nil, err
+		}
+		parser.logger.Printf("Ignore LOCAL protocol block error: %s", err)
+		header, err = &Header{}, nil
+	}
+
+	protocol := metaBuf[protocolPos]
+	header.Version = Version2
+	header.Command = command
+	header.AddressFamily = protocol & BinaryAFMask
+	header.Transport = protocol & BinaryTPMask
+	header.Raw = make([]byte, 0, BinarySignatureLen+len(metaBuf)+len(addressesBuf))
+	header.Raw = append(header.Raw, BinarySignature...)
+	header.Raw = append(header.Raw, metaBuf...)
+	header.Raw = append(header.Raw, addressesBuf...)
+
+	return header, err
+}
+
+// readHeader read signature, meta and addresses block with TLVs
+func (parser BinaryHeaderParser) readHeader(buf *bufio.Reader) ([]byte, []byte, error) {
 	magicBuf, err := buf.Peek(BinarySignatureLen)
 	if err != nil {
 		parser.logger.Printf("Read magic prefix error: %s", err)
-		return nil, err
+		return nil, nil, err
 	}
 
 	if !bytes.Equal(magicBuf, BinarySignature) {
-		return nil, ErrInvalidSignature
+		return nil, nil, ErrInvalidSignature
 	}
 
 	_, err = buf.Discard(BinarySignatureLen)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	metaBuf := make([]byte, addressLenEndPos)
-	if _, err = buf.Read(metaBuf); err != nil {
+	if _, err = io.ReadFull(buf, metaBuf); err != nil {
 		parser.logger.Printf("Read meta error: %s", err)
-		return nil, err
+		return nil, nil, err
 	}
 
-	versionCommandByte := metaBuf[versionCommandPos]
-
-	if versionCommandByte&BinaryVersionMask != BinaryVersion2 {
-		return nil, ErrUnknownVersion
+	if metaBuf[versionCommandPos]&BinaryVersionMask != BinaryVersion2 {
+		return nil, nil, ErrUnknownVersion
 	}
 
 	addressSizeBuf := metaBuf[addressLenStartPos:addressLenEndPos]
@@ -69,40 +121,85 @@ func (parser BinaryHeaderParser) Parse(buf *bufio.Reader) (*Header, error) {
 	parser.logger.Printf("Addresses len: %d", addressesLen)
 
 	addressesBuf := make([]byte, addressesLen)
-	addressReaded, err := buf.Read(addressesBuf)
+	addressReaded, err := io.ReadFull(buf, addressesBuf)
 	if err != nil {
 		parser.logger.Printf("Read address error: %s", err)
-		return nil, err
+		return nil, nil, err
 	}
 	parser.logger.Printf("Address readed: %d", addressReaded)
 
-	switch versionCommandByte & BinaryCommandMask {
-	case BinaryCommandProxy:
-		return parserBinaryCommandHeader(metaBuf[protocolPos], addressesBuf)
-	case BinaryCommandLocal:
-		return nil, nil
-	default:
-		return nil, ErrUnknownCommand
+	return metaBuf, addressesBuf, nil
+}
+
+func (parser BinaryHeaderParser) parseProtocolBlock(metaBuf, addressesBuf []byte) (*Header, error) {
+	header, err := parserBinaryCommandHeader(metaBuf[protocolPos], addressesBuf)
+	if err != nil {
+		return nil, err
 	}
+
+	// Corrupted TLVs reported as checksum mismatch rather than decode error
+	checksumErr := verifyChecksum(header, metaBuf, addressesBuf)
+
+	header.Decoded, err = parser.tlvDecoders.decode(header.TLVs)
+	if err != nil {
+		if checksumErr != nil {
+			return header, checksumErr
+		}
+		parser.logger.Printf("Decode TLV error: %s", err)
+		return nil, err
+	}
+
+	return header, checksumErr
 }
 
+// parserBinaryCommandHeader parse addresses and TLVs. For unspec address
+// family whole addresses block parsed as TLVs.
 func parserBinaryCommandHeader(protocol byte, addressesBuf []byte) (*Header, error) {
+	var header *Header
+	var tlvBuf []byte
+	var err error
+
 	switch protocol & BinaryAFMask {
-	case BinaryProtocolUnspec:
-		return nil, nil
+	case BinaryAFUnspec:
+		header, tlvBuf = &Header{}, addressesBuf
 	case BinaryAFInet:
-		return parseAddressData(addressesBuf, net.IPv4len)
+		header, tlvBuf, err = parseAddressData(addressesBuf, net.IPv4len, protocol&BinaryTPMask)
 	case BinaryAFInet6:
-		return parseAddressData(addressesBuf, net.IPv6len)
+		header, tlvBuf, err = parseAddressData(addressesBuf, net.IPv6len, protocol&BinaryTPMask)
+	case BinaryAFUnix:
+		header, tlvBuf, err = parseUnixAddressData(addressesBuf, unixNetwork(protocol))
 	default:
 		return nil, ErrUnknownProtocol
 	}
+	if err != nil {
+		return nil, err
+	}
+
+	header.TLVs, err = parseTLVs(tlvBuf)
+	if err != nil {
+		return nil, err
+	}
+
+	if crc32cTLV, found := header.FindTLV(TLVTypeCRC32C); found && len(crc32cTLV.Value) != TLVCRC32CLen {
+		return nil, ErrInvalidTLVValue
+	}
+
+	if sslTLV, found := header.FindTLV(TLVTypeSSL); found {
+		header.TLS, err = parseSSLTLV(sslTLV.Value)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return header, nil
 }
 
-func parseAddressData(addressesBuf []byte, ipLen int) (*Header, error) {
+// parseAddressData return header with addresses and rest of buffer with TLVs.
+// For datagram transport addresses is *net.UDPAddr, otherwise *net.TCPAddr.
+func parseAddressData(addressesBuf []byte, ipLen int, transport byte) (*Header, []byte, error) {
 	expectedBufSize := 2 * (ipLen + BinaryPortLen)
 	if len(addressesBuf) < expectedBufSize {
-		return nil, ErrUnexpectedAddressLen
+		return nil, nil, ErrUnexpectedAddressLen
 	}
 
 	srcIP := make(net.IP, ipLen)
@@ -117,7 +214,20 @@ func parseAddressData(addressesBuf []byte, ipLen int) (*Header, error) {
 	addressesBuf = addressesBuf[BinaryPortLen:]
 
 	dstPort := binary.BigEndian.Uint16(addressesBuf[:BinaryPortLen])
-	// addressesBuf = addressesBuf[BinaryPortLen:]
+	addressesBuf = addressesBuf[BinaryPortLen:]
+
+	if transport == BinaryTPDgram {
+		return &Header{
+			SrcAddr: &net.UDPAddr{
+				IP:   srcIP,
+				Port: int(srcPort),
+			},
+			DstAddr: &net.UDPAddr{
+				IP:   dstIP,
+				Port: int(dstPort),
+			},
+		}, addressesBuf, nil
+	}
 
 	return &Header{
 		SrcAddr: &net.TCPAddr{
@@ -128,5 +238,42 @@ func parseAddressData(addressesBuf []byte, ipLen int) (*Header, error) {
 			IP:   dstIP,
 			Port: int(dstPort),
 		},
-	}, nil
+	}, addressesBuf, nil
+}
+
+func unixNetwork(protocol byte) string {
+	if protocol&BinaryTPMask == BinaryTPDgram {
+		return "unixgram"
+	}
+	return "unix"
+}
+
+// parseUnixAddressData return header with unix addresses and rest of buffer with TLVs
+func parseUnixAddressData(addressesBuf []byte, network string) (*Header, []byte, error) {
+	expectedBufSize := 2 * BinaryUnixAddrLen
+	if len(addressesBuf) < expectedBufSize {
+		return nil, nil, ErrUnexpectedAddressLen
+	}
+
+	srcPath := parseUnixPath(addressesBuf[:BinaryUnixAddrLen])
+	addressesBuf = addressesBuf[BinaryUnixAddrLen:]
+
+	dstPath := parseUnixPath(addressesBuf[:BinaryUnixAddrLen])
+	addressesBuf = addressesBuf[BinaryUnixAddrLen:]
+
+	return &Header{
+		SrcAddr: &net.UnixAddr{
+			Name: srcPath,
+			Net:  network,
+		},
+		DstAddr: &net.UnixAddr{
+			Name: dstPath,
+			Net:  network,
+		},
+	}, addressesBuf, nil
+}
+
+// parseUnixPath trim NUL padding. Leading NUL of abstract namespace name kept.
+func parseUnixPath(pathBuf []byte) string {
+	return string(bytes.TrimRight(pathBuf, "\x00"))
 }
@@ -1,10 +1,14 @@
 package proxyprotocol_test
 
 import (
+	"bufio"
+	"bytes"
 	"encoding/binary"
 	"io"
 	"net"
+	"reflect"
 	"testing"
+	"testing/iotest"
 
 	"github.com/c0va23/go-proxyprotocol"
 )
@@ -30,7 +34,7 @@ func TestParseV2Header(t *testing.T) {
 	})
 
 	t.Run("meta EOF", func(t *testing.T) {
-		data := append(proxyprotocol.BinarySignature)
+		data := proxyprotocol.BinarySignature
 		testParser(t, testParserArgs{
 			headerParser: binaryHeaderParser,
 			data:         data,
@@ -38,9 +42,18 @@ func TestParseV2Header(t *testing.T) {
 		})
 	})
 
+	t.Run("meta unexpected EOF", func(t *testing.T) {
+		data := append(proxyprotocol.BinarySignature, proxyprotocol.BinaryVersion2)
+		testParser(t, testParserArgs{
+			headerParser: binaryHeaderParser,
+			data:         data,
+			err:          io.ErrUnexpectedEOF,
+		})
+	})
+
 	t.Run("Invalid version", func(t *testing.T) {
 		invalidVersion := byte(0x00)
-		data := append(proxyprotocol.BinarySignature, invalidVersion)
+		data := append(proxyprotocol.BinarySignature, invalidVersion, 0x00, 0x00, 0x00)
 		testParser(t, testParserArgs{
 			headerParser: binaryHeaderParser,
 			data:         data,
@@ -51,7 +64,7 @@ func TestParseV2Header(t *testing.T) {
 	t.Run("Invalid command", func(t *testing.T) {
 		invalidCommand := proxyprotocol.BinaryVersion2&proxyprotocol.BinaryVersionMask | proxyprotocol.BinaryCommandMask&0xFF
 		t.Logf("Version command bits: %02x", invalidCommand)
-		data := append(proxyprotocol.BinarySignature, invalidCommand)
+		data := append(proxyprotocol.BinarySignature, invalidCommand, 0x00, 0x00, 0x00)
 		testParser(t, testParserArgs{
 			headerParser: binaryHeaderParser,
 			data:         data,
@@ -66,9 +79,13 @@ func TestParseV2Header(t *testing.T) {
 		testParser(t, testParserArgs{
 			headerParser: binaryHeaderParser,
 			data:         data,
-			header:       nil,
-			err:          nil,
-			readAll:      true,
+			header: &proxyprotocol.Header{
+				Version: proxyprotocol.Version2,
+				Command: proxyprotocol.CommandLocal,
+				Raw:     data,
+			},
+			err:     nil,
+			readAll: true,
 		})
 	})
 
@@ -90,9 +107,52 @@ func TestParseV2Header(t *testing.T) {
 		testParser(t, testParserArgs{
 			headerParser: binaryHeaderParser,
 			data:         data,
-			header:       nil,
-			err:          nil,
-			readAll:      true,
+			header: &proxyprotocol.Header{
+				Version: proxyprotocol.Version2,
+				Command: proxyprotocol.CommandLocal,
+				TLVs: []proxyprotocol.TLV{
+					{Type: proxyprotocol.TLVTypeNoop, Value: []byte{}},
+				},
+				Raw: data,
+			},
+			err:     nil,
+			readAll: true,
+		})
+	})
+
+	t.Run("Local command with addresses", func(t *testing.T) {
+		commandVersion := proxyprotocol.BinaryCommandLocal | proxyprotocol.BinaryVersion2
+		data := buildBinaryHeader(commandVersion, proxyprotocol.BinaryProtocolTCPoverIPv4, testIPv4AddressData, nil)
+		testParser(t, testParserArgs{
+			headerParser: binaryHeaderParser,
+			data:         data,
+			header: &proxyprotocol.Header{
+				SrcAddr:       testIPv4SrcAddr,
+				DstAddr:       testIPv4DstAddr,
+				Version:       proxyprotocol.Version2,
+				Command:       proxyprotocol.CommandLocal,
+				AddressFamily: proxyprotocol.BinaryAFInet,
+				Transport:     proxyprotocol.BinaryTPStream,
+				Raw:           data,
+			},
+			readAll: true,
+		})
+	})
+
+	t.Run("Local command with invalid protocol", func(t *testing.T) {
+		commandVersion := proxyprotocol.BinaryCommandLocal | proxyprotocol.BinaryVersion2
+		data := buildBinaryHeader(commandVersion, 0xFF, []byte{1, 2, 3}, nil)
+		testParser(t, testParserArgs{
+			headerParser: binaryHeaderParser,
+			data:         data,
+			header: &proxyprotocol.Header{
+				Version:       proxyprotocol.Version2,
+				Command:       proxyprotocol.CommandLocal,
+				AddressFamily: 0xF0,
+				Transport:     0x0F,
+				Raw:           data,
+			},
+			readAll: true,
 		})
 	})
 
@@ -114,15 +174,20 @@ func TestParseV2Header(t *testing.T) {
 		testParser(t, testParserArgs{
 			headerParser: binaryHeaderParser,
 			data:         data,
-			header:       nil,
-			err:          nil,
-			readAll:      true,
+			header: &proxyprotocol.Header{
+				Version: proxyprotocol.Version2,
+				Command: proxyprotocol.CommandProxy,
+				Raw:     data,
+			},
+			err:     nil,
+			readAll: true,
 		})
 	})
 
 	t.Run("TCPv4 protocol", func(t *testing.T) {
 		commandVersion := proxyprotocol.BinaryCommandProxy | proxyprotocol.BinaryVersion2
 		data := append(proxyprotocol.BinarySignature, commandVersion, proxyprotocol.BinaryProtocolTCPoverIPv4)
+		family := proxyprotocol.BinaryAFInet
 
 		t.Run("Invalid address size", func(t *testing.T) {
 			invalidData := append(data, 0, 0)
@@ -169,11 +234,16 @@ func TestParseV2Header(t *testing.T) {
 						IP:   dstAddr,
 						Port: dstPort,
 					},
+					Version:       proxyprotocol.Version2,
+					Command:       proxyprotocol.CommandProxy,
+					AddressFamily: family,
+					Transport:     proxyprotocol.BinaryTPStream,
 				}
 				validData := append(invalidData, srcAddr...)
 				validData = append(validData, dstAddr...)
 				validData = append(validData, srcPortBuf...)
 				validData = append(validData, dstPortBuf...)
+				expectedHeader.Raw = validData
 				testParser(t, testParserArgs{
 					headerParser: binaryHeaderParser,
 					data:         validData,
@@ -187,6 +257,7 @@ func TestParseV2Header(t *testing.T) {
 	t.Run("TCPv6 protocol", func(t *testing.T) {
 		commandVersion := proxyprotocol.BinaryCommandProxy | proxyprotocol.BinaryVersion2
 		data := append(proxyprotocol.BinarySignature, commandVersion, proxyprotocol.BinaryProtocolTCPoverIPv6)
+		family := proxyprotocol.BinaryAFInet6
 
 		t.Run("Invalid address size", func(t *testing.T) {
 			invalidData := append(data, 0, 0)
@@ -233,11 +304,16 @@ func TestParseV2Header(t *testing.T) {
 						IP:   dstAddr,
 						Port: dstPort,
 					},
+					Version:       proxyprotocol.Version2,
+					Command:       proxyprotocol.CommandProxy,
+					AddressFamily: family,
+					Transport:     proxyprotocol.BinaryTPStream,
 				}
 				validData := append(invalidData, srcAddr...)
 				validData = append(validData, dstAddr...)
 				validData = append(validData, srcPortBuf...)
 				validData = append(validData, dstPortBuf...)
+				expectedHeader.Raw = validData
 				testParser(t, testParserArgs{
 					headerParser: binaryHeaderParser,
 					data:         validData,
@@ -276,12 +352,20 @@ func TestParseV2Header(t *testing.T) {
 						IP:   dstAddr,
 						Port: dstPort,
 					},
+					Version:       proxyprotocol.Version2,
+					Command:       proxyprotocol.CommandProxy,
+					AddressFamily: family,
+					Transport:     proxyprotocol.BinaryTPStream,
+					TLVs: []proxyprotocol.TLV{
+						{Type: proxyprotocol.TLVTypeNoop, Value: []byte{}},
+					},
 				}
 				validData := append(dataWithLen, srcAddr...)
 				validData = append(validData, dstAddr...)
 				validData = append(validData, srcPortBuf...)
 				validData = append(validData, dstPortBuf...)
 				validData = append(validData, proxyprotocol.TLVTypeNoop, 0, 0)
+				expectedHeader.Raw = validData
 				testParser(t, testParserArgs{
 					headerParser: binaryHeaderParser,
 					data:         validData,
@@ -291,4 +375,201 @@ func TestParseV2Header(t *testing.T) {
 			})
 		})
 	})
+
+	t.Run("TCPv4 protocol with TLVs", func(t *testing.T) {
+		commandVersion := proxyprotocol.BinaryCommandProxy | proxyprotocol.BinaryVersion2
+
+		buildData := func(tlvData []byte) []byte {
+			return buildBinaryHeader(commandVersion, proxyprotocol.BinaryProtocolTCPoverIPv4, testIPv4AddressData, tlvData)
+		}
+
+		t.Run("multiple TLVs", func(t *testing.T) {
+			tlvData := []byte{
+				0xE0, 0, 3, 'a', 'b', 'c',
+				proxyprotocol.TLVTypeNoop, 0, 1, 0,
+				0xE0, 0, 1, 'd',
+			}
+			data := buildData(tlvData)
+			testParser(t, testParserArgs{
+				headerParser: binaryHeaderParser,
+				data:         data,
+				header: &proxyprotocol.Header{
+					SrcAddr:       testIPv4SrcAddr,
+					DstAddr:       testIPv4DstAddr,
+					Version:       proxyprotocol.Version2,
+					Command:       proxyprotocol.CommandProxy,
+					AddressFamily: proxyprotocol.BinaryAFInet,
+					Transport:     proxyprotocol.BinaryTPStream,
+					Raw:           data,
+					TLVs: []proxyprotocol.TLV{
+						{Type: 0xE0, Value: []byte("abc")},
+						{Type: proxyprotocol.TLVTypeNoop, Value: []byte{0}},
+						{Type: 0xE0, Value: []byte("d")},
+					},
+				},
+				readAll: true,
+			})
+		})
+
+		t.Run("truncated TLV meta", func(t *testing.T) {
+			testParser(t, testParserArgs{
+				headerParser: binaryHeaderParser,
+				data:         buildData([]byte{0xE0, 0}),
+				err:          proxyprotocol.ErrInvalidTLVLen,
+				readAll:      true,
+			})
+		})
+
+		t.Run("TLV value exceed address block", func(t *testing.T) {
+			testParser(t, testParserArgs{
+				headerParser: binaryHeaderParser,
+				data:         buildData([]byte{0xE0, 0, 4, 'a', 'b', 'c'}),
+				err:          proxyprotocol.ErrInvalidTLVLen,
+				readAll:      true,
+			})
+		})
+	})
+}
+
+func buildUnixAddressData(srcPath, dstPath string) []byte {
+	addressData := make([]byte, 2*proxyprotocol.BinaryUnixAddrLen)
+	copy(addressData, srcPath)
+	copy(addressData[proxyprotocol.BinaryUnixAddrLen:], dstPath)
+	return addressData
+}
+
+func TestParseV2Header_Unix(t *testing.T) {
+	logger := proxyprotocol.LoggerFunc(t.Logf)
+	binaryHeaderParser := proxyprotocol.NewBinaryHeaderParser(logger)
+	commandVersion := proxyprotocol.BinaryCommandProxy | proxyprotocol.BinaryVersion2
+
+	t.Run("Invalid address size", func(t *testing.T) {
+		testParser(t, testParserArgs{
+			headerParser: binaryHeaderParser,
+			data:         buildBinaryHeader(commandVersion, proxyprotocol.BinaryProtocolUnixStream, make([]byte, 108), nil),
+			err:          proxyprotocol.ErrUnexpectedAddressLen,
+			readAll:      true,
+		})
+	})
+
+	t.Run("Unix stream", func(t *testing.T) {
+		addressData := buildUnixAddressData("/var/run/client.sock", "/var/run/server.sock")
+		tlvData := buildTLV(proxyprotocol.TLVTypeNoop, nil)
+		data := buildBinaryHeader(commandVersion, proxyprotocol.BinaryProtocolUnixStream, addressData, tlvData)
+		testParser(t, testParserArgs{
+			headerParser: binaryHeaderParser,
+			data:         data,
+			header: &proxyprotocol.Header{
+				SrcAddr:       &net.UnixAddr{Name: "/var/run/client.sock", Net: "unix"},
+				DstAddr:       &net.UnixAddr{Name: "/var/run/server.sock", Net: "unix"},
+				Version:       proxyprotocol.Version2,
+				Command:       proxyprotocol.CommandProxy,
+				AddressFamily: proxyprotocol.BinaryAFUnix,
+				Transport:     proxyprotocol.BinaryTPStream,
+				Raw:           data,
+				TLVs: []proxyprotocol.TLV{
+					{Type: proxyprotocol.TLVTypeNoop, Value: []byte{}},
+				},
+			},
+			readAll: true,
+		})
+	})
+
+	t.Run("Unix datagram", func(t *testing.T) {
+		addressData := buildUnixAddressData("/tmp/client", "/tmp/server")
+		data := buildBinaryHeader(commandVersion, proxyprotocol.BinaryProtocolUnixDatagram, addressData, nil)
+		testParser(t, testParserArgs{
+			headerParser: binaryHeaderParser,
+			data:         data,
+			header: &proxyprotocol.Header{
+				SrcAddr:       &net.UnixAddr{Name: "/tmp/client", Net: "unixgram"},
+				DstAddr:       &net.UnixAddr{Name: "/tmp/server", Net: "unixgram"},
+				Version:       proxyprotocol.Version2,
+				Command:       proxyprotocol.CommandProxy,
+				AddressFamily: proxyprotocol.BinaryAFUnix,
+				Transport:     proxyprotocol.BinaryTPDgram,
+				Raw:           data,
+			},
+			readAll: true,
+		})
+	})
+
+	t.Run("Unix abstract namespace", func(t *testing.T) {
+		addressData := buildUnixAddressData("\x00client", "")
+		data := buildBinaryHeader(commandVersion, proxyprotocol.BinaryProtocolUnixStream, addressData, nil)
+		testParser(t, testParserArgs{
+			headerParser: binaryHeaderParser,
+			data:         data,
+			header: &proxyprotocol.Header{
+				SrcAddr:       &net.UnixAddr{Name: "\x00client", Net: "unix"},
+				DstAddr:       &net.UnixAddr{Name: "", Net: "unix"},
+				Version:       proxyprotocol.Version2,
+				Command:       proxyprotocol.CommandProxy,
+				AddressFamily: proxyprotocol.BinaryAFUnix,
+				Transport:     proxyprotocol.BinaryTPStream,
+				Raw:           data,
+			},
+			readAll: true,
+		})
+	})
+}
+
+func TestParseV2Header_UDP(t *testing.T) {
+	logger := proxyprotocol.LoggerFunc(t.Logf)
+	binaryHeaderParser := proxyprotocol.NewBinaryHeaderParser(logger)
+	commandVersion := proxyprotocol.BinaryCommandProxy | proxyprotocol.BinaryVersion2
+
+	t.Run("UDP over IPv4", func(t *testing.T) {
+		data := buildBinaryHeader(commandVersion, proxyprotocol.BinaryProtocolUDPoverIPv4, testIPv4AddressData, nil)
+		testParser(t, testParserArgs{
+			headerParser: binaryHeaderParser,
+			data:         data,
+			header: &proxyprotocol.Header{
+				SrcAddr:       &net.UDPAddr{IP: testIPv4SrcAddr.IP, Port: testIPv4SrcAddr.Port},
+				DstAddr:       &net.UDPAddr{IP: testIPv4DstAddr.IP, Port: testIPv4DstAddr.Port},
+				Version:       proxyprotocol.Version2,
+				Command:       proxyprotocol.CommandProxy,
+				AddressFamily: proxyprotocol.BinaryAFInet,
+				Transport:     proxyprotocol.BinaryTPDgram,
+				Raw:           data,
+			},
+			readAll: true,
+		})
+	})
+
+	t.Run("UDP over IPv6", func(t *testing.T) {
+		srcIP := net.ParseIP("2001:db8::1")
+		dstIP := net.ParseIP("2001:db8::2")
+		addressData := append(append(srcIP, dstIP...), 0x30, 0x39, 0x00, 0x35)
+		data := buildBinaryHeader(commandVersion, proxyprotocol.BinaryProtocolUDPoverIPv6, addressData, nil)
+		testParser(t, testParserArgs{
+			headerParser: binaryHeaderParser,
+			data:         data,
+			header: &proxyprotocol.Header{
+				SrcAddr:       &net.UDPAddr{IP: srcIP, Port: 12345},
+				DstAddr:       &net.UDPAddr{IP: dstIP, Port: 53},
+				Version:       proxyprotocol.Version2,
+				Command:       proxyprotocol.CommandProxy,
+				AddressFamily: proxyprotocol.BinaryAFInet6,
+				Transport:     proxyprotocol.BinaryTPDgram,
+				Raw:           data,
+			},
+			readAll: true,
+		})
+	})
+}
+
+func TestParseV2Header_oneByteReads(t *testing.T) {
+	binaryHeaderParser := proxyprotocol.NewBinaryHeaderParser(proxyprotocol.LoggerFunc(t.Logf))
+	commandVersion := proxyprotocol.BinaryVersion2 | proxyprotocol.BinaryCommandProxy
+	data := buildBinaryHeader(commandVersion, proxyprotocol.BinaryProtocolTCPoverIPv4, testIPv4AddressData, nil)
+
+	buf := bufio.NewReader(iotest.OneByteReader(bytes.NewReader(data)))
+	header, err := binaryHeaderParser.Parse(buf)
+	if err != nil {
+		t.Fatalf("Unexpected error %s", err)
+	}
+	if !reflect.DeepEqual(header.SrcAddr, testIPv4SrcAddr) || !reflect.DeepEqual(header.DstAddr, testIPv4DstAddr) {
+		t.Errorf("Unexpected addresses %s, %s", header.SrcAddr, header.DstAddr)
+	}
 }
@@ -0,0 +1,217 @@
+package proxyprotocol
+
+import (
+	"encoding/binary"
+	"io"
+	"net"
+)
+
+// BinaryFormatOptions control optional parts of proxyprotocol v2 header
+type BinaryFormatOptions struct {
+	// Align pad header with NOOP TLV to length multiple of Align bytes.
+	// Zero disable padding.
+	Align int
+	// Checksum append PP2_TYPE_CRC32C TLV, when header not contain it.
+	// Existing PP2_TYPE_CRC32C TLV filled with checksum always.
+	Checksum bool
+}
+
+var maxAddressesLen = 1<<16 - 1
+
+// FormatV2 encode header into proxyprotocol v2 (binary) format without
+// padding and additional checksum.
+func (header *Header) FormatV2() ([]byte, error) {
+	return header.FormatV2WithOptions(BinaryFormatOptions{})
+}
+
+// WriteV2 write proxyprotocol v2 header into writer
+func (header *Header) WriteV2(writer io.Writer) error {
+	headerBuf, err := header.FormatV2()
+	if err != nil {
+		return err
+	}
+
+	_, err = writer.Write(headerBuf)
+	return err
+}
+
+// FormatV2WithOptions encode header into proxyprotocol v2 (binary) format.
+//
+// Header without addresses encoded with unspec protocol. Otherwise addresses
+// must be *net.TCPAddr, *net.UDPAddr or *net.UnixAddr of the same type.
+// TLVs encoded in Header.TLVs order.
+func (header *Header) FormatV2WithOptions(options BinaryFormatOptions) ([]byte, error) {
+	protocol, addressesBuf, err := header.binaryAddresses()
+	if err != nil {
+		return nil, err
+	}
+
+	headerBuf := make([]byte, 0, BinarySignatureLen+addressLenEndPos+len(addressesBuf))
+	headerBuf = append(headerBuf, BinarySignature...)
+	headerBuf = append(headerBuf, BinaryVersion2|header.binaryCommand(), protocol, 0, 0)
+	headerBuf = append(headerBuf, addressesBuf...)
+
+	checksumOffset := -1
+	for _, tlv := range header.TLVs {
+		if tlv.Type == TLVTypeCRC32C && checksumOffset < 0 {
+			checksumOffset = len(headerBuf) + tlvMetaLen
+			tlv.Value = make([]byte, TLVCRC32CLen)
+		}
+		if headerBuf, err = appendTLV(headerBuf, tlv); err != nil {
+			return nil, err
+		}
+	}
+
+	appendChecksum := options.Checksum && checksumOffset < 0
+	paddingLen := binaryPaddingLen(len(headerBuf), options.Align, appendChecksum)
+	if paddingLen > 0 {
+		headerBuf, err = appendTLV(headerBuf, TLV{
+			Type:  TLVTypeNoop,
+			Value: make([]byte, paddingLen-tlvMetaLen),
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if appendChecksum {
+		checksumOffset = len(headerBuf) + tlvMetaLen
+		headerBuf, _ = appendTLV(headerBuf, TLV{
+			Type:  TLVTypeCRC32C,
+			Value: make([]byte, TLVCRC32CLen),
+		})
+	}
+
+	addressesLen := len(headerBuf) - BinarySignatureLen - addressLenEndPos
+	if addressesLen > maxAddressesLen {
+		return nil, ErrHeaderTooLong
+	}
+	binary.BigEndian.PutUint16(
+		headerBuf[BinarySignatureLen+addressLenStartPos:BinarySignatureLen+addressLenEndPos],
+		uint16(addressesLen),
+	)
+
+	if checksumOffset >= 0 {
+		checksum := updateChecksum(0, headerBuf, checksumOffset)
+		binary.BigEndian.PutUint32(headerBuf[checksumOffset:], checksum)
+	}
+
+	return headerBuf, nil
+}
+
+func (header *Header) binaryCommand() byte {
+	if header.Command == CommandLocal {
+		return BinaryCommandLocal
+	}
+	return BinaryCommandProxy
+}
+
+// binaryAddresses return protocol byte and encoded addresses
+func (header *Header) binaryAddresses() (byte, []byte, error) {
+	switch srcAddr := header.SrcAddr.(type) {
+	case nil:
+		if header.DstAddr != nil {
+			return 0, nil, ErrInvalidAddressFamily
+		}
+		return BinaryProtocolUnspec, nil, nil
+	case *net.TCPAddr:
+		dstAddr, ok := header.DstAddr.(*net.TCPAddr)
+		if !ok {
+			return 0, nil, ErrInvalidAddressFamily
+		}
+		return header.binaryIPAddresses(BinaryTPStream, srcAddr.IP, dstAddr.IP, srcAddr.Port, dstAddr.Port)
+	case *net.UDPAddr:
+		dstAddr, ok := header.DstAddr.(*net.UDPAddr)
+		if !ok {
+			return 0, nil, ErrInvalidAddressFamily
+		}
+		return header.binaryIPAddresses(BinaryTPDgram, srcAddr.IP, dstAddr.IP, srcAddr.Port, dstAddr.Port)
+	case *net.UnixAddr:
+		dstAddr, ok := header.DstAddr.(*net.UnixAddr)
+		if !ok {
+			return 0, nil, ErrInvalidAddressFamily
+		}
+		return binaryUnixAddresses(srcAddr, dstAddr)
+	default:
+		return 0, nil, ErrUnsupportedAddress
+	}
+}
+
+func (header *Header) binaryIPAddresses(
+	transport byte,
+	srcIP, dstIP net.IP,
+	srcPort, dstPort int,
+) (byte, []byte, error) {
+	addressFamily, err := header.ipAddressFamily(srcIP, dstIP)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	if !validPort(srcPort) || !validPort(dstPort) {
+		return 0, nil, ErrInvalidPort
+	}
+
+	if addressFamily == BinaryAFInet {
+		srcIP, dstIP = srcIP.To4(), dstIP.To4()
+	} else {
+		srcIP, dstIP = srcIP.To16(), dstIP.To16()
+	}
+
+	addressesBuf := make([]byte, 0, 2*(len(srcIP)+BinaryPortLen))
+	addressesBuf = append(addressesBuf, srcIP...)
+	addressesBuf = append(addressesBuf, dstIP...)
+	addressesBuf = appendUint16(addressesBuf, srcPort)
+	addressesBuf = appendUint16(addressesBuf, dstPort)
+
+	return addressFamily | transport, addressesBuf, nil
+}
+
+func binaryUnixAddresses(srcAddr, dstAddr *net.UnixAddr) (byte, []byte, error) {
+	if len(srcAddr.Name) > BinaryUnixAddrLen || len(dstAddr.Name) > BinaryUnixAddrLen {
+		return 0, nil, ErrUnexpectedAddressLen
+	}
+
+	addressesBuf := make([]byte, 2*BinaryUnixAddrLen)
+	copy(addressesBuf, srcAddr.Name)
+	copy(addressesBuf[BinaryUnixAddrLen:], dstAddr.Name)
+
+	transport := BinaryTPStream
+	if srcAddr.Net == "unixgram" {
+		transport = BinaryTPDgram
+	}
+
+	return BinaryAFUnix | transport, addressesBuf, nil
+}
+
+func appendTLV(headerBuf []byte, tlv TLV) ([]byte, error) {
+	if len(tlv.Value) > maxAddressesLen {
+		return nil, ErrHeaderTooLong
+	}
+
+	headerBuf = append(headerBuf, tlv.Type)
+	headerBuf = appendUint16(headerBuf, len(tlv.Value))
+	return append(headerBuf, tlv.Value...), nil
+}
+
+func appendUint16(buf []byte, value int) []byte {
+	return append(buf, byte(value>>8), byte(value))
+}
+
+// binaryPaddingLen return length of NOOP TLV required to align header.
+// NOOP TLV can not be shorter than TLV meta.
+func binaryPaddingLen(headerLen, align int, appendChecksum bool) int {
+	if align <= 0 {
+		return 0
+	}
+
+	if appendChecksum {
+		headerLen += tlvMetaLen + TLVCRC32CLen
+	}
+
+	paddingLen := (align - headerLen%align) % align
+	for paddingLen > 0 && paddingLen < tlvMetaLen {
+		paddingLen += align
+	}
+
+	return paddingLen
+}
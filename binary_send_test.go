@@ -0,0 +1,213 @@
+package proxyprotocol_test
+
+import (
+	"bytes"
+	"net"
+	"reflect"
+	"testing"
+
+	"github.com/c0va23/go-proxyprotocol"
+)
+
+func TestHeader_FormatV2(t *testing.T) {
+	logger := proxyprotocol.LoggerFunc(t.Logf)
+	binaryHeaderParser := proxyprotocol.NewBinaryHeaderParser(logger)
+
+	testRoundTrip := func(t *testing.T, header *proxyprotocol.Header, options proxyprotocol.BinaryFormatOptions) *proxyprotocol.Header {
+		headerBuf, err := header.FormatV2WithOptions(options)
+		if err != nil {
+			t.Fatalf("Unexpected error %s", err)
+		}
+
+		readBuf := newTestReader(headerBuf)
+		parsedHeader, err := binaryHeaderParser.Parse(readBuf)
+		if err != nil {
+			t.Fatalf("Unexpected error %s", err)
+		}
+
+		if readBuf.Buffered() != 0 {
+			t.Errorf("Header not readed")
+		}
+
+		if !bytes.Equal(parsedHeader.Raw, headerBuf) {
+			t.Errorf("Unexpected raw header %v", parsedHeader.Raw)
+		}
+
+		if !reflect.DeepEqual(parsedHeader.SrcAddr, header.SrcAddr) ||
+			!reflect.DeepEqual(parsedHeader.DstAddr, header.DstAddr) {
+			t.Errorf("Unexpected addresses %s, %s", parsedHeader.SrcAddr, parsedHeader.DstAddr)
+		}
+
+		if parsedHeader.Command != header.Command {
+			t.Errorf("Unexpected command %s", parsedHeader.Command)
+		}
+
+		formattedBuf, err := parsedHeader.FormatV2()
+		if err != nil {
+			t.Fatalf("Unexpected error %s", err)
+		}
+
+		if !bytes.Equal(formattedBuf, headerBuf) {
+			t.Errorf("Unexpected formatted header %v, expected %v", formattedBuf, headerBuf)
+		}
+
+		return parsedHeader
+	}
+
+	t.Run("without addresses", func(t *testing.T) {
+		header := proxyprotocol.Header{}
+		parsedHeader := testRoundTrip(t, &header, proxyprotocol.BinaryFormatOptions{})
+		if parsedHeader.AddressFamily != proxyprotocol.BinaryAFUnspec {
+			t.Errorf("Unexpected address family %x", parsedHeader.AddressFamily)
+		}
+	})
+
+	t.Run("LOCAL command", func(t *testing.T) {
+		header := proxyprotocol.Header{Command: proxyprotocol.CommandLocal}
+		testRoundTrip(t, &header, proxyprotocol.BinaryFormatOptions{})
+	})
+
+	addressPairs := map[string][2]net.Addr{
+		"TCP over IPv4": {testIPv4SrcAddr, testIPv4DstAddr},
+		"TCP over IPv6": {
+			&net.TCPAddr{IP: net.ParseIP("2001:db8::1"), Port: 12345},
+			&net.TCPAddr{IP: net.ParseIP("2001:db8::2"), Port: 443},
+		},
+		"UDP over IPv4": {
+			&net.UDPAddr{IP: net.IP{192, 168, 1, 2}, Port: 12345},
+			&net.UDPAddr{IP: net.IP{10, 0, 0, 2}, Port: 53},
+		},
+		"UDP over IPv6": {
+			&net.UDPAddr{IP: net.ParseIP("2001:db8::1"), Port: 12345},
+			&net.UDPAddr{IP: net.ParseIP("2001:db8::2"), Port: 53},
+		},
+		"Unix stream": {
+			&net.UnixAddr{Name: "/tmp/client.sock", Net: "unix"},
+			&net.UnixAddr{Name: "\x00server", Net: "unix"},
+		},
+		"Unix datagram": {
+			&net.UnixAddr{Name: "/tmp/client.sock", Net: "unixgram"},
+			&net.UnixAddr{Name: "/tmp/server.sock", Net: "unixgram"},
+		},
+	}
+
+	for name, addresses := range addressPairs {
+		header := proxyprotocol.Header{
+			SrcAddr: addresses[0],
+			DstAddr: addresses[1],
+			TLVs: []proxyprotocol.TLV{
+				{Type: proxyprotocol.TLVTypeAuthority, Value: []byte("example.com")},
+				{Type: 0xE0, Value: []byte{}},
+			},
+		}
+		t.Run(name, func(t *testing.T) {
+			parsedHeader := testRoundTrip(t, &header, proxyprotocol.BinaryFormatOptions{})
+			if !reflect.DeepEqual(parsedHeader.TLVs, header.TLVs) {
+				t.Errorf("Unexpected TLVs %v", parsedHeader.TLVs)
+			}
+		})
+	}
+
+	t.Run("with padding", func(t *testing.T) {
+		for _, align := range []int{1, 2, 16, 64} {
+			for _, checksum := range []bool{false, true} {
+				header := proxyprotocol.Header{
+					SrcAddr: testIPv4SrcAddr,
+					DstAddr: testIPv4DstAddr,
+					TLVs:    []proxyprotocol.TLV{{Type: 0xE0, Value: []byte("x")}},
+				}
+				options := proxyprotocol.BinaryFormatOptions{Align: align, Checksum: checksum}
+				headerBuf, err := header.FormatV2WithOptions(options)
+				if err != nil {
+					t.Fatalf("Unexpected error %s", err)
+				}
+
+				if len(headerBuf)%align != 0 {
+					t.Errorf("Unexpected header length %d for %+v", len(headerBuf), options)
+				}
+
+				testRoundTrip(t, &header, options)
+			}
+		}
+	})
+
+	t.Run("with checksum", func(t *testing.T) {
+		header := proxyprotocol.Header{
+			SrcAddr: testIPv4SrcAddr,
+			DstAddr: testIPv4DstAddr,
+		}
+		options := proxyprotocol.BinaryFormatOptions{Checksum: true}
+		parsedHeader := testRoundTrip(t, &header, options)
+
+		if _, found := parsedHeader.FindTLV(proxyprotocol.TLVTypeCRC32C); !found {
+			t.Fatal("Checksum TLV not found")
+		}
+
+		t.Run("when address changed", func(t *testing.T) {
+			headerBuf, err := parsedHeader.FormatV2()
+			if err != nil {
+				t.Fatalf("Unexpected error %s", err)
+			}
+
+			headerBuf[len(proxyprotocol.BinarySignature)+4]++
+
+			if _, err := binaryHeaderParser.Parse(newTestReader(headerBuf)); err != proxyprotocol.ErrChecksumMismatch {
+				t.Errorf("Unexpected error %v", err)
+			}
+		})
+	})
+
+	t.Run("mixed addresses types", func(t *testing.T) {
+		header := proxyprotocol.Header{
+			SrcAddr: testIPv4SrcAddr,
+			DstAddr: &net.UDPAddr{IP: net.IP{10, 0, 0, 2}, Port: 53},
+		}
+		if _, err := header.FormatV2(); err != proxyprotocol.ErrInvalidAddressFamily {
+			t.Errorf("Unexpected error %v", err)
+		}
+	})
+
+	t.Run("too long unix address", func(t *testing.T) {
+		header := proxyprotocol.Header{
+			SrcAddr: &net.UnixAddr{Name: string(make([]byte, proxyprotocol.BinaryUnixAddrLen+1)), Net: "unix"},
+			DstAddr: &net.UnixAddr{Name: "/tmp/server.sock", Net: "unix"},
+		}
+		if _, err := header.FormatV2(); err != proxyprotocol.ErrUnexpectedAddressLen {
+			t.Errorf("Unexpected error %v", err)
+		}
+	})
+
+	t.Run("too long TLVs", func(t *testing.T) {
+		header := proxyprotocol.Header{
+			TLVs: []proxyprotocol.TLV{
+				{Type: 0xE0, Value: make([]byte, 1<<15)},
+				{Type: 0xE0, Value: make([]byte, 1<<15)},
+			},
+		}
+		if _, err := header.FormatV2(); err != proxyprotocol.ErrHeaderTooLong {
+			t.Errorf("Unexpected error %v", err)
+		}
+	})
+}
+
+func TestHeader_WriteV2(t *testing.T) {
+	header := proxyprotocol.Header{
+		SrcAddr: testIPv4SrcAddr,
+		DstAddr: testIPv4DstAddr,
+	}
+
+	var buf bytes.Buffer
+	if err := header.WriteV2(&buf); err != nil {
+		t.Fatalf("Unexpected error %s", err)
+	}
+
+	expectedBuf := buildBinaryHeader(
+		proxyprotocol.BinaryVersion2|proxyprotocol.BinaryCommandProxy,
+		proxyprotocol.BinaryProtocolTCPoverIPv4,
+		testIPv4AddressData,
+		nil,
+	)
+	if !bytes.Equal(buf.Bytes(), expectedBuf) {
+		t.Errorf("Unexpected header %v", buf.Bytes())
+	}
+}
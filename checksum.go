@@ -0,0 +1,114 @@
+package proxyprotocol
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+)
+
+// ErrChecksumMismatch returned when PP2_TYPE_CRC32C TLV not match header checksum
+var ErrChecksumMismatch = errors.New("checksum mismatch")
+
+var crc32cTable = crc32.MakeTable(crc32.Castagnoli)
+
+// ChecksumPolicy define how Listener handle headers with mismatched
+// PP2_TYPE_CRC32C checksum.
+type ChecksumPolicy int
+
+// Checksum policies
+const (
+	// ChecksumPolicyEnforce reject header with ErrChecksumMismatch
+	ChecksumPolicyEnforce ChecksumPolicy = iota
+	// ChecksumPolicyLogOnly log mismatch and accept header
+	ChecksumPolicyLogOnly
+	// ChecksumPolicyIgnore silently accept header
+	ChecksumPolicyIgnore
+)
+
+// checksumPolicyHeaderParser apply ChecksumPolicy to ErrChecksumMismatch
+// returned by inner HeaderParser.
+type checksumPolicyHeaderParser struct {
+	HeaderParser
+	logger Logger
+	policy ChecksumPolicy
+}
+
+// Parse call inner HeaderParser and accept header with mismatched checksum
+// when policy allow it.
+func (parser checksumPolicyHeaderParser) Parse(buf *bufio.Reader) (*Header, error) {
+	header, err := parser.HeaderParser.Parse(buf)
+	if err != ErrChecksumMismatch {
+		return header, err
+	}
+
+	switch parser.policy {
+	case ChecksumPolicyLogOnly:
+		parser.logger.Printf("Header checksum mismatch")
+		return header, nil
+	case ChecksumPolicyIgnore:
+		return header, nil
+	default:
+		return nil, err
+	}
+}
+
+// verifyChecksum compare PP2_TYPE_CRC32C value with checksum of header.
+// Header without PP2_TYPE_CRC32C TLV always valid.
+func verifyChecksum(header *Header, metaBuf, addressesBuf []byte) error {
+	valueOffset, found := checksumValueOffset(addressesBuf, header.TLVs)
+	if !found {
+		return nil
+	}
+
+	checksumValue := addressesBuf[valueOffset : valueOffset+TLVCRC32CLen]
+	expectedChecksum := binary.BigEndian.Uint32(checksumValue)
+
+	checksum := crc32.Update(0, crc32cTable, BinarySignature)
+	checksum = crc32.Update(checksum, crc32cTable, metaBuf)
+	checksum = updateChecksum(checksum, addressesBuf, valueOffset)
+
+	if checksum != expectedChecksum {
+		return ErrChecksumMismatch
+	}
+
+	return nil
+}
+
+// updateChecksum update checksum with buf, where checksum value on
+// valueOffset replaced with zeros
+func updateChecksum(checksum uint32, buf []byte, valueOffset int) uint32 {
+	checksum = crc32.Update(checksum, crc32cTable, buf[:valueOffset])
+	checksum = crc32.Update(checksum, crc32cTable, make([]byte, TLVCRC32CLen))
+	return crc32.Update(checksum, crc32cTable, buf[valueOffset+TLVCRC32CLen:])
+}
+
+// ChecksumValuePos return position of PP2_TYPE_CRC32C value in Raw bytes of
+// parsed binary header.
+func (header *Header) ChecksumValuePos() (int, bool) {
+	pos, found := checksumValueOffset(header.Raw, header.TLVs)
+	if !found || pos < 0 || pos+TLVCRC32CLen > len(header.Raw) {
+		return 0, false
+	}
+	return pos, true
+}
+
+// checksumValueOffset return offset of PP2_TYPE_CRC32C value in addressesBuf.
+// TLVs always placed at the end of addressesBuf.
+func checksumValueOffset(addressesBuf []byte, tlvs []TLV) (int, bool) {
+	tlvsLen := 0
+	for _, tlv := range tlvs {
+		tlvsLen += tlvMetaLen + len(tlv.Value)
+	}
+
+	offset := len(addressesBuf) - tlvsLen
+	for _, tlv := range tlvs {
+		offset += tlvMetaLen
+		if tlv.Type == TLVTypeCRC32C {
+			return offset, true
+		}
+		offset += len(tlv.Value)
+	}
+
+	return 0, false
+}
@@ -0,0 +1,134 @@
+package proxyprotocol_test
+
+import (
+	"encoding/binary"
+	"hash/crc32"
+	"net"
+	"reflect"
+	"testing"
+
+	"github.com/c0va23/go-proxyprotocol"
+	"github.com/golang/mock/gomock"
+)
+
+func buildChecksumHeader(valid bool) []byte {
+	commandVersion := proxyprotocol.BinaryCommandProxy | proxyprotocol.BinaryVersion2
+	tlvData := append(
+		buildTLV(proxyprotocol.TLVTypeAuthority, []byte("example.com")),
+		buildTLV(proxyprotocol.TLVTypeCRC32C, make([]byte, proxyprotocol.TLVCRC32CLen))...,
+	)
+	data := buildBinaryHeader(commandVersion, proxyprotocol.BinaryProtocolTCPoverIPv4, testIPv4AddressData, tlvData)
+
+	checksum := crc32.Checksum(data, crc32.MakeTable(crc32.Castagnoli))
+	if !valid {
+		checksum++
+	}
+	binary.BigEndian.PutUint32(data[len(data)-proxyprotocol.TLVCRC32CLen:], checksum)
+
+	return data
+}
+
+func TestBinaryHeaderParser_Parse_checksum(t *testing.T) {
+	logger := proxyprotocol.LoggerFunc(t.Logf)
+	binaryHeaderParser := proxyprotocol.NewBinaryHeaderParser(logger)
+
+	t.Run("valid checksum", func(t *testing.T) {
+		header, err := binaryHeaderParser.Parse(newTestReader(buildChecksumHeader(true)))
+		if err != nil {
+			t.Errorf("Unexpected error %s", err)
+		}
+
+		if header == nil || !reflect.DeepEqual(header.SrcAddr, testIPv4SrcAddr) {
+			t.Errorf("Unexpected header %+v", header)
+		}
+	})
+
+	t.Run("invalid checksum", func(t *testing.T) {
+		header, err := binaryHeaderParser.Parse(newTestReader(buildChecksumHeader(false)))
+		if err != proxyprotocol.ErrChecksumMismatch {
+			t.Errorf("Unexpected error %v", err)
+		}
+
+		if header == nil || !reflect.DeepEqual(header.SrcAddr, testIPv4SrcAddr) {
+			t.Errorf("Unexpected header %+v", header)
+		}
+	})
+
+	t.Run("checksum value position", func(t *testing.T) {
+		data := buildChecksumHeader(true)
+		header, err := binaryHeaderParser.Parse(newTestReader(data))
+		if err != nil {
+			t.Fatalf("Unexpected error %s", err)
+		}
+
+		if pos, found := header.ChecksumValuePos(); !found || pos != len(data)-proxyprotocol.TLVCRC32CLen {
+			t.Errorf("Unexpected position %d, found %t", pos, found)
+		}
+	})
+
+	t.Run("invalid checksum length", func(t *testing.T) {
+		commandVersion := proxyprotocol.BinaryCommandProxy | proxyprotocol.BinaryVersion2
+		tlvData := buildTLV(proxyprotocol.TLVTypeCRC32C, []byte{0, 0})
+		testParser(t, testParserArgs{
+			headerParser: binaryHeaderParser,
+			data:         buildBinaryHeader(commandVersion, proxyprotocol.BinaryProtocolTCPoverIPv4, testIPv4AddressData, tlvData),
+			err:          proxyprotocol.ErrInvalidTLVValue,
+			readAll:      true,
+		})
+	})
+}
+
+func TestListener_WithChecksumPolicy(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	rawListener := NewMockListener(mockCtrl)
+	listener := proxyprotocol.NewListener(rawListener, proxyprotocol.BinaryHeaderParserBuilder)
+
+	acceptWithPolicy := func(checksumPolicy proxyprotocol.ChecksumPolicy) net.Conn {
+		serverConn, clientConn := net.Pipe()
+		rawListener.EXPECT().Accept().Return(serverConn, nil)
+
+		go func() {
+			defer clientConn.Close()
+			if _, err := clientConn.Write(buildChecksumHeader(false)); err != nil {
+				t.Logf("Write error: %s", err)
+			}
+		}()
+
+		conn, err := listener.
+			WithLogger(proxyprotocol.LoggerFunc(t.Logf)).
+			WithChecksumPolicy(checksumPolicy).
+			Accept()
+		if err != nil {
+			t.Fatalf("Unexpected error %s", err)
+		}
+		return conn
+	}
+
+	t.Run("enforce", func(t *testing.T) {
+		conn := acceptWithPolicy(proxyprotocol.ChecksumPolicyEnforce)
+		defer conn.Close()
+
+		if _, err := conn.Read(make([]byte, 1)); err != proxyprotocol.ErrChecksumMismatch {
+			t.Errorf("Unexpected error %v", err)
+		}
+
+		if remoteAddr := conn.RemoteAddr(); reflect.DeepEqual(remoteAddr, testIPv4SrcAddr) {
+			t.Errorf("Unexpected remote addr %s", remoteAddr)
+		}
+	})
+
+	for _, checksumPolicy := range []proxyprotocol.ChecksumPolicy{
+		proxyprotocol.ChecksumPolicyLogOnly,
+		proxyprotocol.ChecksumPolicyIgnore,
+	} {
+		conn := acceptWithPolicy(checksumPolicy)
+
+		if remoteAddr := conn.RemoteAddr(); !reflect.DeepEqual(remoteAddr, testIPv4SrcAddr) {
+			t.Errorf("Unexpected remote addr %s with policy %d", remoteAddr, checksumPolicy)
+		}
+
+		conn.Close()
+	}
+}
@@ -0,0 +1,82 @@
+package proxyprotocol
+
+import (
+	"encoding/binary"
+)
+
+// Cloud vendor TLV value length
+const (
+	tlvSubtypeLen   = 1
+	azureLinkIDLen  = 4
+	gcpPSCConnIDLen = 8
+)
+
+// AWSVPCEndpointID return VPC endpoint ID from PP2_TYPE_AWS TLV sent by AWS
+// Network Load Balancer.
+func (header *Header) AWSVPCEndpointID() (string, error) {
+	for _, tlv := range header.FilterTLVs(TLVTypeAWS) {
+		if len(tlv.Value) >= tlvSubtypeLen && tlv.Value[0] == TLVSubtypeAWSVPCEndpointID {
+			return DecodeAWSVPCEndpointID(tlv.Value)
+		}
+	}
+	return "", ErrTLVNotFound
+}
+
+// AzureLinkID return Private Link LinkID from PP2_TYPE_AZURE TLV.
+func (header *Header) AzureLinkID() (uint32, error) {
+	for _, tlv := range header.FilterTLVs(TLVTypeAzure) {
+		if len(tlv.Value) >= tlvSubtypeLen && tlv.Value[0] == TLVSubtypeAzureLinkID {
+			return DecodeAzureLinkID(tlv.Value)
+		}
+	}
+	return 0, ErrTLVNotFound
+}
+
+// GCPPSCConnectionID return Private Service Connect connection ID from
+// PP2_TYPE_GCP TLV.
+func (header *Header) GCPPSCConnectionID() (uint64, error) {
+	tlv, found := header.FindTLV(TLVTypeGCP)
+	if !found {
+		return 0, ErrTLVNotFound
+	}
+	return DecodeGCPPSCConnectionID(tlv.Value)
+}
+
+// DecodeAWSVPCEndpointID decode PP2_TYPE_AWS TLV value with
+// PP2_SUBTYPE_AWS_VPCE_ID subtype. Value is subtype byte followed by
+// US-ASCII endpoint ID (vpce-...).
+func DecodeAWSVPCEndpointID(value []byte) (string, error) {
+	if len(value) <= tlvSubtypeLen || value[0] != TLVSubtypeAWSVPCEndpointID {
+		return "", ErrInvalidTLVValue
+	}
+
+	endpointID := value[tlvSubtypeLen:]
+	for _, char := range endpointID {
+		if char < ' ' || char > '~' {
+			return "", ErrInvalidTLVValue
+		}
+	}
+
+	return string(endpointID), nil
+}
+
+// DecodeAzureLinkID decode PP2_TYPE_AZURE TLV value with
+// PP2_SUBTYPE_AZURE_PRIVATEENDPOINT_LINKID subtype. Value is subtype byte
+// followed by little-endian 32-bit LinkID.
+func DecodeAzureLinkID(value []byte) (uint32, error) {
+	if len(value) != tlvSubtypeLen+azureLinkIDLen || value[0] != TLVSubtypeAzureLinkID {
+		return 0, ErrInvalidTLVValue
+	}
+
+	return binary.LittleEndian.Uint32(value[tlvSubtypeLen:]), nil
+}
+
+// DecodeGCPPSCConnectionID decode PP2_TYPE_GCP TLV value. Value is
+// big-endian 64-bit connection ID.
+func DecodeGCPPSCConnectionID(value []byte) (uint64, error) {
+	if len(value) != gcpPSCConnIDLen {
+		return 0, ErrInvalidTLVValue
+	}
+
+	return binary.BigEndian.Uint64(value), nil
+}
@@ -0,0 +1,106 @@
+package proxyprotocol_test
+
+import (
+	"testing"
+
+	"github.com/c0va23/go-proxyprotocol"
+)
+
+func TestHeader_AWSVPCEndpointID(t *testing.T) {
+	t.Run("when TLV not exists", func(t *testing.T) {
+		header := proxyprotocol.Header{
+			TLVs: []proxyprotocol.TLV{{Type: proxyprotocol.TLVTypeAWS, Value: []byte{0x02, 'x'}}},
+		}
+		if _, err := header.AWSVPCEndpointID(); err != proxyprotocol.ErrTLVNotFound {
+			t.Errorf("Unexpected error %v", err)
+		}
+	})
+
+	t.Run("when TLV empty", func(t *testing.T) {
+		header := proxyprotocol.Header{
+			TLVs: []proxyprotocol.TLV{{Type: proxyprotocol.TLVTypeAWS, Value: []byte{proxyprotocol.TLVSubtypeAWSVPCEndpointID}}},
+		}
+		if _, err := header.AWSVPCEndpointID(); err != proxyprotocol.ErrInvalidTLVValue {
+			t.Errorf("Unexpected error %v", err)
+		}
+	})
+
+	t.Run("when TLV valid", func(t *testing.T) {
+		value := append([]byte{proxyprotocol.TLVSubtypeAWSVPCEndpointID}, "vpce-08d2bf15fac5001c9"...)
+		header := proxyprotocol.Header{
+			TLVs: []proxyprotocol.TLV{
+				{Type: proxyprotocol.TLVTypeAWS, Value: []byte{0x02, 'x'}},
+				{Type: proxyprotocol.TLVTypeAWS, Value: value},
+			},
+		}
+		endpointID, err := header.AWSVPCEndpointID()
+		if err != nil {
+			t.Errorf("Unexpected error %s", err)
+		}
+		if endpointID != "vpce-08d2bf15fac5001c9" {
+			t.Errorf("Unexpected endpoint ID %q", endpointID)
+		}
+	})
+}
+
+func TestHeader_AzureLinkID(t *testing.T) {
+	t.Run("when TLV not exists", func(t *testing.T) {
+		header := proxyprotocol.Header{}
+		if _, err := header.AzureLinkID(); err != proxyprotocol.ErrTLVNotFound {
+			t.Errorf("Unexpected error %v", err)
+		}
+	})
+
+	t.Run("when TLV has invalid length", func(t *testing.T) {
+		header := proxyprotocol.Header{
+			TLVs: []proxyprotocol.TLV{{Type: proxyprotocol.TLVTypeAzure, Value: []byte{proxyprotocol.TLVSubtypeAzureLinkID, 1, 2}}},
+		}
+		if _, err := header.AzureLinkID(); err != proxyprotocol.ErrInvalidTLVValue {
+			t.Errorf("Unexpected error %v", err)
+		}
+	})
+
+	t.Run("when TLV valid", func(t *testing.T) {
+		header := proxyprotocol.Header{
+			TLVs: []proxyprotocol.TLV{{Type: proxyprotocol.TLVTypeAzure, Value: []byte{proxyprotocol.TLVSubtypeAzureLinkID, 0x78, 0x56, 0x34, 0x12}}},
+		}
+		linkID, err := header.AzureLinkID()
+		if err != nil {
+			t.Errorf("Unexpected error %s", err)
+		}
+		if linkID != 0x12345678 {
+			t.Errorf("Unexpected link ID %x", linkID)
+		}
+	})
+}
+
+func TestHeader_GCPPSCConnectionID(t *testing.T) {
+	t.Run("when TLV not exists", func(t *testing.T) {
+		header := proxyprotocol.Header{}
+		if _, err := header.GCPPSCConnectionID(); err != proxyprotocol.ErrTLVNotFound {
+			t.Errorf("Unexpected error %v", err)
+		}
+	})
+
+	t.Run("when TLV has invalid length", func(t *testing.T) {
+		header := proxyprotocol.Header{
+			TLVs: []proxyprotocol.TLV{{Type: proxyprotocol.TLVTypeGCP, Value: []byte("custom")}},
+		}
+		if _, err := header.GCPPSCConnectionID(); err != proxyprotocol.ErrInvalidTLVValue {
+			t.Errorf("Unexpected error %v", err)
+		}
+	})
+
+	t.Run("when TLV valid", func(t *testing.T) {
+		header := proxyprotocol.Header{
+			TLVs: []proxyprotocol.TLV{{Type: proxyprotocol.TLVTypeGCP, Value: []byte{0, 0, 0, 0, 0, 0, 0x01, 0x02}}},
+		}
+		connectionID, err := header.GCPPSCConnectionID()
+		if err != nil {
+			t.Errorf("Unexpected error %s", err)
+		}
+		if connectionID != 0x0102 {
+			t.Errorf("Unexpected connection ID %x", connectionID)
+		}
+	})
+}
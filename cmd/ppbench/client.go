@@ -0,0 +1,219 @@
+package main
+
+import (
+	"io"
+	"math/rand"
+	"net"
+	"os"
+	"syscall"
+	"time"
+
+	"github.com/c0va23/go-proxyprotocol"
+	"github.com/c0va23/go-proxyprotocol/internal/headerflags"
+)
+
+// Client kinds
+const (
+	kindNormal    = "normal"
+	kindSlow      = "slow"
+	kindTruncated = "truncated"
+	kindOversized = "oversized"
+)
+
+var pathologicalKinds = []string{kindSlow, kindTruncated, kindOversized}
+
+// Connection outcomes
+const (
+	outcomeResponse = "response"
+	outcomeClosed   = "closed"
+	outcomeReset    = "reset"
+	outcomeTimeout  = "timeout"
+	outcomeError    = "error"
+)
+
+// clientConfig is settings shared by all connections
+type clientConfig struct {
+	target      string
+	version     byte
+	payload     []byte
+	timeout     time.Duration
+	trickleStep time.Duration
+}
+
+// result of one connection
+type result struct {
+	kind    string
+	outcome string
+	// acceptLatency is time of TCP connection establishment. Zero when dial
+	// failed.
+	acceptLatency time.Duration
+	// ttfb is time from request end to first response byte
+	ttfb time.Duration
+	err  error
+}
+
+// runClient open connection, send header of kind and payload and wait first
+// response byte. Oversized clients always send v2 header.
+func runClient(config clientConfig, kind string, random *rand.Rand) result {
+	res := result{kind: kind}
+
+	version := config.version
+	if kind == kindOversized {
+		version = proxyprotocol.Version2
+	}
+
+	headerBuf, err := randomHeader(version, random)
+	if err == nil {
+		headerBuf, err = malformKind(headerBuf, kind)
+	}
+	if err != nil {
+		res.outcome, res.err = outcomeError, err
+		return res
+	}
+
+	dialStart := time.Now()
+	conn, err := net.DialTimeout("tcp", config.target, config.timeout)
+	if err != nil {
+		res.outcome, res.err = classifyError(err), err
+		return res
+	}
+	res.acceptLatency = time.Since(dialStart)
+	defer conn.Close()
+
+	if err := conn.SetDeadline(time.Now().Add(config.timeout)); err != nil {
+		res.outcome, res.err = outcomeError, err
+		return res
+	}
+
+	if err := sendRequest(conn, config, kind, headerBuf); err != nil {
+		res.outcome, res.err = classifyError(err), err
+		return res
+	}
+
+	requestEnd := time.Now()
+	_, err = conn.Read(make([]byte, 1))
+	if err != nil {
+		res.outcome, res.err = classifyError(err), err
+		return res
+	}
+
+	res.ttfb = time.Since(requestEnd)
+	res.outcome = outcomeResponse
+	return res
+}
+
+// sendRequest write header and payload. Slow client write header by one byte.
+// Truncated and oversized clients close write side after header, because
+// payload would be read as header continuation.
+func sendRequest(conn net.Conn, config clientConfig, kind string, headerBuf []byte) error {
+	switch kind {
+	case kindSlow:
+		for i := range headerBuf {
+			if _, err := conn.Write(headerBuf[i : i+1]); err != nil {
+				return err
+			}
+			time.Sleep(config.trickleStep)
+		}
+	case kindTruncated, kindOversized:
+		if _, err := conn.Write(headerBuf); err != nil {
+			return err
+		}
+		if tcpConn, ok := conn.(*net.TCPConn); ok {
+			return tcpConn.CloseWrite()
+		}
+		return nil
+	default:
+		if _, err := conn.Write(headerBuf); err != nil {
+			return err
+		}
+	}
+
+	_, err := conn.Write(config.payload)
+	return err
+}
+
+func malformKind(headerBuf []byte, kind string) ([]byte, error) {
+	switch kind {
+	case kindTruncated:
+		return headerflags.Malform(headerBuf, headerflags.MalformTruncate)
+	case kindOversized:
+		return headerflags.Malform(headerBuf, headerflags.MalformOversized)
+	default:
+		return headerBuf, nil
+	}
+}
+
+// randomHeader build header with random addresses. Zero version mean random
+// version. Binary headers contain random TLVs.
+func randomHeader(version byte, random *rand.Rand) ([]byte, error) {
+	if version == 0 {
+		version = proxyprotocol.Version1 + byte(random.Intn(2))
+	}
+
+	ipLen := net.IPv4len
+	if random.Intn(2) == 0 {
+		ipLen = net.IPv6len
+	}
+
+	header := &proxyprotocol.Header{
+		SrcAddr: &net.TCPAddr{IP: randomIP(ipLen, random), Port: 1024 + random.Intn(64511)},
+		DstAddr: &net.TCPAddr{IP: randomIP(ipLen, random), Port: 1 + random.Intn(65535)},
+	}
+
+	if version == proxyprotocol.Version1 {
+		return header.FormatV1()
+	}
+
+	if random.Intn(2) == 0 {
+		header.TLVs = append(header.TLVs, proxyprotocol.TLV{
+			Type:  proxyprotocol.TLVTypeAuthority,
+			Value: []byte("bench.example.com"),
+		})
+	}
+	if random.Intn(2) == 0 {
+		uniqueID := make([]byte, 1+random.Intn(proxyprotocol.TLVUniqueIDMaxLen))
+		random.Read(uniqueID) // nolint: errcheck
+		header.TLVs = append(header.TLVs, proxyprotocol.TLV{
+			Type:  proxyprotocol.TLVTypeUniqueID,
+			Value: uniqueID,
+		})
+	}
+
+	return header.FormatV2WithOptions(proxyprotocol.BinaryFormatOptions{
+		Checksum: random.Intn(2) == 0,
+	})
+}
+
+func randomIP(ipLen int, random *rand.Rand) net.IP {
+	ip := make(net.IP, ipLen)
+	random.Read(ip) // nolint: errcheck
+	if ipLen == net.IPv6len {
+		// Avoid IPv4-mapped addresses, which change address family
+		ip[0] = 0x20
+	}
+	return ip
+}
+
+// classifyError return outcome of connection error
+func classifyError(err error) string {
+	if netErr, ok := err.(net.Error); ok && netErr.Timeout() {
+		return outcomeTimeout
+	}
+
+	cause := err
+	if opErr, ok := cause.(*net.OpError); ok {
+		cause = opErr.Err
+	}
+	if sysErr, ok := cause.(*os.SyscallError); ok {
+		cause = sysErr.Err
+	}
+
+	switch {
+	case err == io.EOF:
+		return outcomeClosed
+	case cause == syscall.ECONNRESET || cause == syscall.EPIPE:
+		return outcomeReset
+	default:
+		return outcomeError
+	}
+}
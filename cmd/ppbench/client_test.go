@@ -0,0 +1,109 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"math/rand"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/c0va23/go-proxyprotocol"
+)
+
+// startServer start proxyprotocol server, which answer to first byte of
+// payload
+func startServer(t *testing.T) (string, func()) {
+	rawListener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	listener := proxyprotocol.NewDefaultListener(rawListener).
+		WithLogger(proxyprotocol.LoggerFunc(t.Logf))
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				if _, err := conn.Read(make([]byte, 1)); err != nil {
+					return
+				}
+				conn.Write([]byte("OK")) // nolint: errcheck
+			}()
+		}
+	}()
+
+	return rawListener.Addr().String(), func() { rawListener.Close() }
+}
+
+func TestRunClient(t *testing.T) {
+	address, stop := startServer(t)
+	defer stop()
+
+	config := clientConfig{
+		target:  address,
+		payload: []byte("PING\n"),
+		timeout: time.Second,
+	}
+	random := rand.New(rand.NewSource(1))
+
+	testCases := []struct {
+		kind    string
+		version byte
+		outcome string
+	}{
+		{kindNormal, proxyprotocol.Version1, outcomeResponse},
+		{kindNormal, proxyprotocol.Version2, outcomeResponse},
+		{kindSlow, proxyprotocol.Version1, outcomeResponse},
+		{kindSlow, proxyprotocol.Version2, outcomeResponse},
+		{kindTruncated, proxyprotocol.Version1, outcomeClosed},
+		{kindTruncated, proxyprotocol.Version2, outcomeClosed},
+		{kindOversized, proxyprotocol.Version1, outcomeClosed},
+	}
+
+	for _, testCase := range testCases {
+		config.version = testCase.version
+		res := runClient(config, testCase.kind, random)
+		if res.outcome != testCase.outcome {
+			t.Errorf("Unexpected %s v%d outcome %s (%v)", testCase.kind, testCase.version, res.outcome, res.err)
+		}
+		if res.acceptLatency <= 0 {
+			t.Errorf("Unexpected %s v%d accept latency %s", testCase.kind, testCase.version, res.acceptLatency)
+		}
+	}
+}
+
+func TestRunClient_dialError(t *testing.T) {
+	address, stop := startServer(t)
+	stop()
+
+	res := runClient(clientConfig{target: address, timeout: time.Second}, kindNormal, rand.New(rand.NewSource(1)))
+	if res.err == nil || res.acceptLatency != 0 {
+		t.Errorf("Unexpected result %+v", res)
+	}
+}
+
+func TestRandomHeader(t *testing.T) {
+	random := rand.New(rand.NewSource(1))
+	parser := proxyprotocol.DefaultFallbackHeaderParserBuilder.Build(proxyprotocol.LoggerFunc(t.Logf))
+
+	for i := 0; i < 100; i++ {
+		headerBuf, err := randomHeader(0, random)
+		if err != nil {
+			t.Fatalf("Unexpected error %s", err)
+		}
+
+		header, err := parser.Parse(bufio.NewReader(bytes.NewReader(headerBuf)))
+		if err != nil {
+			t.Fatalf("Parse error %s for %q", err, headerBuf)
+		}
+		if header.SrcAddr == nil || header.DstAddr == nil {
+			t.Errorf("Unexpected header %+v", header)
+		}
+	}
+}
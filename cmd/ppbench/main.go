@@ -0,0 +1,155 @@
+// Command ppbench open many concurrent connections with random proxyprotocol
+// headers to server and report accept latency, time to first byte, error and
+// reset counts. Part of connections are pathological clients: slow header
+// trickle, truncated header and oversized v2 address length.
+//
+// Usage:
+//
+//	ppbench -c 100 -n 10000 localhost:8080
+//	ppbench -c 50 -duration 30s -pathological 0.2 -payload 'PING\n' localhost:9000
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"math/rand"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+)
+
+func main() {
+	var (
+		concurrency  int
+		count        int
+		duration     time.Duration
+		version      uint
+		payload      string
+		pathological float64
+		timeout      time.Duration
+		trickleStep  time.Duration
+		seed         int64
+		verbose      bool
+	)
+	flag.IntVar(&concurrency, "c", 50, "Number of concurrent connections")
+	flag.IntVar(&count, "n", 1000, "Total number of connections (ignored with -duration)")
+	flag.DurationVar(&duration, "duration", 0, "Run for duration instead of fixed number of connections")
+	flag.UintVar(&version, "version", 0, "Header version 1 or 2 (0 mean random)")
+	flag.StringVar(&payload, "payload", `GET / HTTP/1.0\r\n\r\n`, "Payload sent after header (Go escape sequences allowed)")
+	flag.Float64Var(&pathological, "pathological", 0.1, "Fraction of pathological clients (0..1)")
+	flag.DurationVar(&timeout, "timeout", 5*time.Second, "Connection timeout")
+	flag.DurationVar(&trickleStep, "trickle", 10*time.Millisecond, "Delay between header bytes of slow clients")
+	flag.Int64Var(&seed, "seed", time.Now().UnixNano(), "Random seed")
+	flag.BoolVar(&verbose, "verbose", false, "Log every failed connection")
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s [flags] HOST:PORT\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if flag.NArg() != 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+	if version > 2 {
+		log.Fatalf("Unknown version %d", version)
+	}
+	if concurrency < 1 {
+		log.Fatal("Concurrency should be positive")
+	}
+
+	payloadBuf, err := strconv.Unquote(`"` + payload + `"`)
+	if err != nil {
+		log.Fatalf("Invalid payload: %s", err)
+	}
+
+	config := clientConfig{
+		target:      flag.Arg(0),
+		version:     byte(version),
+		payload:     []byte(payloadBuf),
+		timeout:     timeout,
+		trickleStep: trickleStep,
+	}
+
+	log.Printf("Run %d concurrent clients against %s (seed %d)", concurrency, config.target, seed)
+
+	start := time.Now()
+	results := run(config, concurrency, newSchedule(count, duration), pathological, seed)
+
+	benchStats := newStats()
+	for res := range results {
+		if verbose && res.err != nil {
+			log.Printf("%s client %s: %s", res.kind, res.outcome, res.err)
+		}
+		benchStats.add(res)
+	}
+
+	if err := benchStats.write(os.Stdout, time.Since(start)); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// schedule decide when to stop starting new connections
+type schedule struct {
+	mutex    sync.Mutex
+	left     int
+	deadline time.Time
+}
+
+// newSchedule for fixed count of connections or, when duration not zero, for
+// duration
+func newSchedule(count int, duration time.Duration) *schedule {
+	if duration > 0 {
+		return &schedule{left: -1, deadline: time.Now().Add(duration)}
+	}
+	return &schedule{left: count}
+}
+
+// next return false when no more connections should be started
+func (s *schedule) next() bool {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if !s.deadline.IsZero() {
+		return time.Now().Before(s.deadline)
+	}
+	if s.left <= 0 {
+		return false
+	}
+	s.left--
+	return true
+}
+
+// run start workers and return channel of results, closed when all workers
+// finished
+func run(config clientConfig, concurrency int, sched *schedule, pathological float64, seed int64) <-chan result {
+	results := make(chan result, concurrency)
+
+	var wg sync.WaitGroup
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+		go func(random *rand.Rand) {
+			defer wg.Done()
+			for sched.next() {
+				results <- runClient(config, pickKind(random, pathological), random)
+			}
+		}(rand.New(rand.NewSource(seed + int64(i))))
+	}
+
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	return results
+}
+
+// pickKind return pathological kind with given probability, otherwise normal
+func pickKind(random *rand.Rand, pathological float64) string {
+	if random.Float64() >= pathological {
+		return kindNormal
+	}
+	return pathologicalKinds[random.Intn(len(pathologicalKinds))]
+}
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"text/tabwriter"
+	"time"
+)
+
+// durations is sortable list of measured durations
+type durations []time.Duration
+
+func (d durations) Len() int           { return len(d) }
+func (d durations) Less(i, j int) bool { return d[i] < d[j] }
+func (d durations) Swap(i, j int)      { d[i], d[j] = d[j], d[i] }
+
+// percentile return nearest-rank percentile of sorted durations. For empty
+// list zero returned.
+func (d durations) percentile(p float64) time.Duration {
+	if len(d) == 0 {
+		return 0
+	}
+	rank := int(p/100*float64(len(d))+0.5) - 1
+	if rank < 0 {
+		rank = 0
+	}
+	if rank >= len(d) {
+		rank = len(d) - 1
+	}
+	return d[rank]
+}
+
+func (d durations) summary() string {
+	if len(d) == 0 {
+		return "-"
+	}
+	return fmt.Sprintf("%s/%s/%s/%s",
+		d.percentile(50), d.percentile(90), d.percentile(99), d[len(d)-1])
+}
+
+// kindStats aggregate results of one client kind
+type kindStats struct {
+	total         int
+	outcomes      map[string]int
+	acceptLatency durations
+	ttfb          durations
+	lastErr       error
+}
+
+// stats aggregate results by client kind
+type stats struct {
+	kinds map[string]*kindStats
+}
+
+func newStats() *stats {
+	return &stats{kinds: make(map[string]*kindStats)}
+}
+
+func (s *stats) add(res result) {
+	kind, found := s.kinds[res.kind]
+	if !found {
+		kind = &kindStats{outcomes: make(map[string]int)}
+		s.kinds[res.kind] = kind
+	}
+
+	kind.total++
+	kind.outcomes[res.outcome]++
+	if res.acceptLatency > 0 {
+		kind.acceptLatency = append(kind.acceptLatency, res.acceptLatency)
+	}
+	if res.outcome == outcomeResponse {
+		kind.ttfb = append(kind.ttfb, res.ttfb)
+	}
+	if res.err != nil {
+		kind.lastErr = res.err
+	}
+}
+
+// write report table. Durations printed as p50/p90/p99/max.
+func (s *stats) write(w io.Writer, elapsed time.Duration) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "kind\tconns\tresponse\tclosed\treset\ttimeout\terror\taccept p50/p90/p99/max\tttfb p50/p90/p99/max")
+
+	total := 0
+	for _, name := range append([]string{kindNormal}, pathologicalKinds...) {
+		kind, found := s.kinds[name]
+		if !found {
+			continue
+		}
+		total += kind.total
+		sort.Sort(kind.acceptLatency)
+		sort.Sort(kind.ttfb)
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%d\t%d\t%d\t%d\t%s\t%s\n",
+			name,
+			kind.total,
+			kind.outcomes[outcomeResponse],
+			kind.outcomes[outcomeClosed],
+			kind.outcomes[outcomeReset],
+			kind.outcomes[outcomeTimeout],
+			kind.outcomes[outcomeError],
+			kind.acceptLatency.summary(),
+			kind.ttfb.summary(),
+		)
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	for _, name := range append([]string{kindNormal}, pathologicalKinds...) {
+		if kind, found := s.kinds[name]; found && kind.lastErr != nil {
+			fmt.Fprintf(w, "last %s error: %s\n", name, kind.lastErr)
+		}
+	}
+
+	rate := float64(total) / elapsed.Seconds()
+	_, err := fmt.Fprintf(w, "%d connections in %s (%.1f conn/s)\n", total, elapsed.Round(time.Millisecond), rate)
+	return err
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDurations_percentile(t *testing.T) {
+	var values durations
+	for i := 1; i <= 100; i++ {
+		values = append(values, time.Duration(i)*time.Millisecond)
+	}
+
+	testCases := []struct {
+		percentile float64
+		expected   time.Duration
+	}{
+		{0, time.Millisecond},
+		{50, 50 * time.Millisecond},
+		{90, 90 * time.Millisecond},
+		{99, 99 * time.Millisecond},
+		{100, 100 * time.Millisecond},
+	}
+
+	for _, testCase := range testCases {
+		if value := values.percentile(testCase.percentile); value != testCase.expected {
+			t.Errorf("Unexpected p%v %s", testCase.percentile, value)
+		}
+	}
+
+	if value := (durations{}).percentile(50); value != 0 {
+		t.Errorf("Unexpected empty percentile %s", value)
+	}
+}
+
+func TestStats_write(t *testing.T) {
+	benchStats := newStats()
+	benchStats.add(result{kind: kindNormal, outcome: outcomeResponse, acceptLatency: time.Millisecond, ttfb: 2 * time.Millisecond})
+	benchStats.add(result{kind: kindNormal, outcome: outcomeReset, acceptLatency: time.Millisecond, err: errors.New("reset")})
+	benchStats.add(result{kind: kindTruncated, outcome: outcomeClosed, acceptLatency: time.Millisecond})
+
+	var buf bytes.Buffer
+	if err := benchStats.write(&buf, time.Second); err != nil {
+		t.Fatal(err)
+	}
+
+	lines := strings.Split(buf.String(), "\n")
+	if fields := strings.Fields(lines[1]); strings.Join(fields[:7], " ") != "normal 2 1 0 1 0 0" {
+		t.Errorf("Unexpected normal line %q", lines[1])
+	}
+	if fields := strings.Fields(lines[2]); strings.Join(fields[:7], " ") != "truncated 1 0 1 0 0 0" {
+		t.Errorf("Unexpected truncated line %q", lines[2])
+	}
+	if !strings.Contains(buf.String(), "last normal error: reset") ||
+		!strings.Contains(buf.String(), "3 connections in 1s") {
+		t.Errorf("Unexpected report %q", buf.String())
+	}
+}
@@ -0,0 +1,143 @@
+// Command ppcap report proxyprotocol headers of TCP connections from pcap and
+// pcapng capture files.
+//
+// Usage:
+//
+//	tcpdump -i eth0 -w capture.pcap 'tcp port 8080'
+//	ppcap [-all] [-output text|json] capture.pcap
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"time"
+
+	"github.com/c0va23/go-proxyprotocol"
+	"github.com/c0va23/go-proxyprotocol/internal/headerinfo"
+	"github.com/c0va23/go-proxyprotocol/pcapanalyzer"
+)
+
+// connectionReport is JSON-friendly connection report
+type connectionReport struct {
+	File      string           `json:"file"`
+	FirstSeen time.Time        `json:"first_seen"`
+	LBAddr    string           `json:"lb_addr"`
+	DstAddr   string           `json:"dst_addr"`
+	Handshake bool             `json:"handshake"`
+	Status    string           `json:"status"`
+	Header    *headerinfo.Info `json:"header,omitempty"`
+	Error     string           `json:"error,omitempty"`
+}
+
+func main() {
+	var (
+		all     bool
+		output  string
+		verbose bool
+	)
+	flag.BoolVar(&all, "all", false, "Report connections without header signature")
+	flag.StringVar(&output, "output", "text", "Output format: text or json")
+	flag.BoolVar(&verbose, "verbose", false, "Log parser messages")
+	flag.Parse()
+
+	if flag.NArg() == 0 {
+		fmt.Fprintf(os.Stderr, "Usage: %s [flags] FILE...\n", os.Args[0])
+		flag.PrintDefaults()
+		os.Exit(2)
+	}
+
+	var logger proxyprotocol.Logger
+	if verbose {
+		logger = proxyprotocol.LoggerFunc(log.Printf)
+	}
+
+	reports := []connectionReport{}
+	for _, path := range flag.Args() {
+		connections, err := analyzeFile(path, logger)
+		if err != nil {
+			log.Printf("Read %s error: %s", path, err)
+		}
+
+		for _, connection := range connections {
+			if !all && (connection.Status == pcapanalyzer.StatusNoHeader || connection.Status == pcapanalyzer.StatusNoData) {
+				continue
+			}
+			reports = append(reports, newConnectionReport(path, connection))
+		}
+	}
+
+	var err error
+	switch output {
+	case "json":
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		err = encoder.Encode(reports)
+	case "text":
+		err = writeText(os.Stdout, reports)
+	default:
+		log.Fatalf("Unknown output format %s", output)
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+func analyzeFile(path string, logger proxyprotocol.Logger) ([]*pcapanalyzer.Connection, error) {
+	captureFile, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer captureFile.Close()
+
+	return pcapanalyzer.Analyze(captureFile, logger)
+}
+
+func newConnectionReport(path string, connection *pcapanalyzer.Connection) connectionReport {
+	report := connectionReport{
+		File:      path,
+		FirstSeen: connection.FirstSeen,
+		LBAddr:    connection.SrcAddr.String(),
+		DstAddr:   connection.DstAddr.String(),
+		Handshake: connection.Handshake,
+		Status:    connection.Status.String(),
+	}
+
+	if connection.Header != nil {
+		info := headerinfo.New(connection.Header)
+		report.Header = &info
+	}
+	if connection.Err != nil {
+		report.Error = connection.Err.Error()
+	}
+
+	return report
+}
+
+func writeText(writer io.Writer, reports []connectionReport) error {
+	for _, report := range reports {
+		_, err := fmt.Fprintf(writer, "%s %s -> %s %s\n",
+			report.FirstSeen.Format(time.RFC3339Nano), report.LBAddr, report.DstAddr, report.Status)
+		if err != nil {
+			return err
+		}
+
+		var lines [][2]string
+		if report.Header != nil {
+			lines = report.Header.Lines()
+		}
+		if report.Error != "" {
+			lines = append(lines, [2]string{"Error", report.Error})
+		}
+		for i := range lines {
+			lines[i][0] = "  " + lines[i][0]
+		}
+		if err := headerinfo.WriteLines(writer, lines); err != nil {
+			return err
+		}
+	}
+	return nil
+}
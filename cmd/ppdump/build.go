@@ -0,0 +1,39 @@
+package main
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/c0va23/go-proxyprotocol/internal/headerflags"
+)
+
+func buildCommand(args []string) error {
+	var (
+		headerFlags headerflags.Flags
+		output      string
+	)
+	flagSet := flag.NewFlagSet("build", flag.ExitOnError)
+	headerFlags.Register(flagSet)
+	flagSet.StringVar(&output, "output", encodingHex, "Output encoding: raw, hex or base64")
+	flagSet.Parse(args) // nolint: errcheck
+
+	headerBuf, err := headerFlags.Format()
+	if err != nil {
+		return err
+	}
+
+	switch output {
+	case encodingRaw:
+		_, err = os.Stdout.Write(headerBuf)
+	case encodingHex:
+		_, err = fmt.Println(hex.EncodeToString(headerBuf))
+	case encodingBase64:
+		_, err = fmt.Println(base64.StdEncoding.EncodeToString(headerBuf))
+	default:
+		return errUnknownEncoding
+	}
+	return err
+}
@@ -0,0 +1,172 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"os"
+	"strings"
+	"unicode"
+
+	"github.com/c0va23/go-proxyprotocol"
+	"github.com/c0va23/go-proxyprotocol/internal/headerinfo"
+)
+
+// Input encodings
+const (
+	encodingAuto   = "auto"
+	encodingRaw    = "raw"
+	encodingHex    = "hex"
+	encodingBase64 = "base64"
+)
+
+var errUnknownEncoding = errors.New("unknown encoding")
+
+// decodeResult is result of header decoding
+type decodeResult struct {
+	Header     *headerinfo.Info `json:"header,omitempty"`
+	Error      string           `json:"error,omitempty"`
+	PayloadLen int              `json:"payload_len"`
+}
+
+func decodeCommand(args []string) error {
+	var (
+		inputEncoding string
+		output        string
+		verbose       bool
+	)
+	flagSet := flag.NewFlagSet("decode", flag.ExitOnError)
+	flagSet.StringVar(&inputEncoding, "input", encodingAuto, "Input encoding: auto, raw, hex or base64")
+	flagSet.StringVar(&output, "output", "text", "Output format: text or json")
+	flagSet.BoolVar(&verbose, "verbose", false, "Log parser messages")
+	flagSet.Parse(args) // nolint: errcheck
+
+	input := io.Reader(os.Stdin)
+	if path := flagSet.Arg(0); path != "" && path != "-" {
+		inputFile, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer inputFile.Close()
+		input = inputFile
+	}
+
+	data, err := ioutil.ReadAll(input)
+	if err != nil {
+		return err
+	}
+
+	headerBuf, err := decodeInput(data, inputEncoding)
+	if err != nil {
+		return err
+	}
+
+	logger := proxyprotocol.FallbackLogger{}
+	if verbose {
+		logger.Logger = proxyprotocol.LoggerFunc(log.Printf)
+	}
+	result := decodeHeader(headerBuf, logger)
+
+	switch output {
+	case "json":
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		err = encoder.Encode(result)
+	case "text":
+		err = result.writeText(os.Stdout)
+	default:
+		return fmt.Errorf("unknown output format %s", output)
+	}
+	if err != nil {
+		return err
+	}
+
+	if result.Error != "" {
+		os.Exit(1)
+	}
+	return nil
+}
+
+// decodeInput decode data from encoding. Auto encoding detect raw header by
+// signature, then try hex and base64.
+func decodeInput(data []byte, encoding string) ([]byte, error) {
+	switch encoding {
+	case encodingRaw:
+		return data, nil
+	case encodingHex:
+		return hex.DecodeString(stripSpaces(string(data)))
+	case encodingBase64:
+		return base64.StdEncoding.DecodeString(stripSpaces(string(data)))
+	case encodingAuto:
+		if bytes.HasPrefix(data, proxyprotocol.TextSignature) ||
+			bytes.HasPrefix(data, proxyprotocol.BinarySignature) {
+			return data, nil
+		}
+		if decoded, err := decodeInput(data, encodingHex); err == nil {
+			return decoded, nil
+		}
+		if decoded, err := decodeInput(data, encodingBase64); err == nil {
+			return decoded, nil
+		}
+		return data, nil
+	default:
+		return nil, errUnknownEncoding
+	}
+}
+
+func stripSpaces(value string) string {
+	return strings.Map(func(char rune) rune {
+		if unicode.IsSpace(char) {
+			return -1
+		}
+		return char
+	}, value)
+}
+
+// decodeHeader parse header with text and binary parsers. Header returned
+// together with error (checksum mismatch) included into result.
+func decodeHeader(headerBuf []byte, logger proxyprotocol.Logger) decodeResult {
+	parser := proxyprotocol.NewFallbackHeaderParser(
+		logger,
+		proxyprotocol.NewTextHeaderParser(logger),
+		proxyprotocol.NewBinaryHeaderParser(logger),
+	)
+
+	reader := bufio.NewReader(bytes.NewReader(headerBuf))
+	header, err := parser.Parse(reader)
+
+	var result decodeResult
+	if header != nil {
+		info := headerinfo.New(header)
+		result.Header = &info
+		result.PayloadLen = len(headerBuf) - len(header.Raw)
+	}
+	switch err {
+	case nil:
+	case io.EOF, io.ErrUnexpectedEOF:
+		result.Error = "truncated header: " + err.Error()
+	default:
+		result.Error = err.Error()
+	}
+
+	return result
+}
+
+func (result decodeResult) writeText(writer io.Writer) error {
+	var lines [][2]string
+	if result.Header != nil {
+		lines = append(result.Header.Lines(), [2]string{"Payload", fmt.Sprintf("%d bytes", result.PayloadLen)})
+	}
+	if result.Error != "" {
+		lines = append(lines, [2]string{"Error", result.Error})
+	}
+	return headerinfo.WriteLines(writer, lines)
+}
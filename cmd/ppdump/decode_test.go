@@ -0,0 +1,55 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/c0va23/go-proxyprotocol"
+)
+
+func TestDecodeInput(t *testing.T) {
+	headerBuf := []byte("PROXY UNKNOWN\r\n")
+
+	inputs := []struct {
+		data     string
+		encoding string
+	}{
+		{"PROXY UNKNOWN\r\n", encodingRaw},
+		{"PROXY UNKNOWN\r\n", encodingAuto},
+		{"50524f5859\n20554e4b4e4f574e0d0a", encodingAuto},
+		{"50 52 4f 58 59 20 55 4e 4b 4e 4f 57 4e 0d 0a", encodingHex},
+		{"UFJPWFkgVU5LTk9XTg0K\n", encodingAuto},
+		{"UFJPWFkgVU5LTk9XTg0K", encodingBase64},
+	}
+	for _, input := range inputs {
+		decoded, err := decodeInput([]byte(input.data), input.encoding)
+		if err != nil || !bytes.Equal(decoded, headerBuf) {
+			t.Errorf("Unexpected result %q, error %v for %q", decoded, err, input.data)
+		}
+	}
+
+	if _, err := decodeInput(nil, "unknown"); err != errUnknownEncoding {
+		t.Errorf("Unexpected error %v", err)
+	}
+}
+
+func TestDecodeHeader(t *testing.T) {
+	logger := proxyprotocol.LoggerFunc(t.Logf)
+
+	t.Run("header with payload", func(t *testing.T) {
+		result := decodeHeader([]byte("PROXY UNKNOWN\r\nGET /"), logger)
+
+		if result.Header == nil || result.Header.Version != proxyprotocol.Version1 ||
+			result.PayloadLen != 5 || result.Error != "" {
+			t.Errorf("Unexpected result %+v", result)
+		}
+	})
+
+	t.Run("truncated header", func(t *testing.T) {
+		result := decodeHeader(proxyprotocol.BinarySignature[:8], logger)
+
+		if result.Header != nil || result.Error != "truncated header: EOF" {
+			t.Errorf("Unexpected result %+v", result)
+		}
+	})
+}
@@ -0,0 +1,41 @@
+// Command ppdump decode proxyprotocol headers from raw, hex or base64 input
+// and build headers from flags for test fixtures.
+//
+// Usage:
+//
+//	ppdump decode [-input auto|raw|hex|base64] [-output text|json] [FILE]
+//	ppdump build [-version 2] [-src 192.168.1.2:12345 -dst 10.0.0.2:8080] [-output hex|base64|raw]
+package main
+
+import (
+	"fmt"
+	"os"
+)
+
+func usage() {
+	fmt.Fprintf(os.Stderr, "Usage:\n")
+	fmt.Fprintf(os.Stderr, "  %s decode [flags] [FILE]\tdecode header from FILE or stdin\n", os.Args[0])
+	fmt.Fprintf(os.Stderr, "  %s build [flags]\tbuild header from flags\n", os.Args[0])
+	os.Exit(2)
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+	}
+
+	var err error
+	switch os.Args[1] {
+	case "decode":
+		err = decodeCommand(os.Args[2:])
+	case "build":
+		err = buildCommand(os.Args[2:])
+	default:
+		usage()
+	}
+
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,34 @@
+package main
+
+import (
+	"errors"
+	"net"
+	"sync"
+)
+
+var errListenerClosed = errors.New("listener closed")
+
+// oneConnListener is net.Listener which accept single connection. Used to
+// serve HTTP with handler bound to connection.
+type oneConnListener struct {
+	conn net.Conn
+	once sync.Once
+}
+
+func (list *oneConnListener) Accept() (net.Conn, error) {
+	var conn net.Conn
+	list.once.Do(func() { conn = list.conn })
+	if conn == nil {
+		return nil, errListenerClosed
+	}
+	return conn, nil
+}
+
+// Close not close accepted connection
+func (list *oneConnListener) Close() error {
+	return nil
+}
+
+func (list *oneConnListener) Addr() net.Addr {
+	return list.conn.LocalAddr()
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"io"
+	"net"
+	"strconv"
+	"time"
+
+	"github.com/c0va23/go-proxyprotocol"
+	"github.com/c0va23/go-proxyprotocol/internal/headerinfo"
+)
+
+// report describe what library extracted from connection
+type report struct {
+	PeerAddr      string           `json:"peer_addr"`
+	LocalAddr     string           `json:"local_addr"`
+	Trusted       bool             `json:"trusted"`
+	RemoteAddr    string           `json:"remote_addr"`
+	ParseDuration string           `json:"parse_duration"`
+	Header        *headerinfo.Info `json:"header,omitempty"`
+	Error         string           `json:"error,omitempty"`
+}
+
+// newReport parse header of conn and build report. Parse duration include
+// waiting of header bytes from network.
+func newReport(conn net.Conn) report {
+	start := time.Now()
+	connHeader, found := proxyprotocol.HeaderFromConn(conn)
+	parseDuration := time.Since(start)
+	if !found {
+		return report{Error: "not proxyprotocol connection"}
+	}
+
+	result := report{
+		PeerAddr:      connHeader.PeerAddr.String(),
+		LocalAddr:     conn.LocalAddr().String(),
+		Trusted:       connHeader.Trusted,
+		RemoteAddr:    conn.RemoteAddr().String(),
+		ParseDuration: parseDuration.String(),
+	}
+
+	if connHeader.Header != nil {
+		info := headerinfo.New(connHeader.Header)
+		result.Header = &info
+	}
+	if connHeader.Err != nil {
+		result.Error = connHeader.Err.Error()
+	}
+
+	return result
+}
+
+func (result report) writeText(writer io.Writer) error {
+	lines := [][2]string{
+		{"Peer", result.PeerAddr},
+		{"Local", result.LocalAddr},
+		{"Trusted", strconv.FormatBool(result.Trusted)},
+		{"Remote", result.RemoteAddr},
+		{"Parse duration", result.ParseDuration},
+	}
+	if result.Header != nil {
+		lines = append(lines, result.Header.Lines()...)
+	} else if result.Error == "" {
+		lines = append(lines, [2]string{"Header", "none"})
+	}
+	if result.Error != "" {
+		lines = append(lines, [2]string{"Error", result.Error})
+	}
+	return headerinfo.WriteLines(writer, lines)
+}
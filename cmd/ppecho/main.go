@@ -0,0 +1,120 @@
+// Command ppecho is diagnostic server which answer with everything library
+// extracted from connection: proxyprotocol header, trust decision, parse
+// duration and raw peer address. HTTP clients receive JSON, other clients
+// (like netcat) receive text.
+//
+// Usage:
+//
+//	ppecho -bind :8080 -trusted 10.0.0.0/8,127.0.0.1/32
+//	curl --haproxy-protocol http://localhost:8080/
+//	ppsend -n localhost:8080
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"log"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/c0va23/go-proxyprotocol"
+	"github.com/c0va23/go-proxyprotocol/internal/acceptretry"
+	"github.com/c0va23/go-proxyprotocol/internal/sniff"
+	"github.com/c0va23/go-proxyprotocol/internal/sourcecheck"
+)
+
+// server settings
+type server struct {
+	headerTimeout time.Duration
+	detectTimeout time.Duration
+}
+
+func main() {
+	var (
+		addr          string
+		trusted       string
+		headerTimeout time.Duration
+		detectTimeout time.Duration
+		verbose       bool
+	)
+	flag.StringVar(&addr, "bind", ":8080", "Bind address")
+	flag.StringVar(&trusted, "trusted", "", "Comma-separated trusted source CIDRs (empty trust all)")
+	flag.DurationVar(&headerTimeout, "header-timeout", 5*time.Second, "Header read timeout")
+	flag.DurationVar(&detectTimeout, "detect-timeout", 200*time.Millisecond, "Time to wait for HTTP request after header")
+	flag.BoolVar(&verbose, "verbose", false, "Log parser messages")
+	flag.Parse()
+
+	sourceChecker, err := sourcecheck.ParseCIDRs(trusted)
+	if err != nil {
+		log.Fatalf("Invalid trusted list: %s", err)
+	}
+
+	rawList, err := net.Listen("tcp", addr)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	list := proxyprotocol.NewDefaultListener(rawList).WithSourceChecker(sourceChecker)
+	if verbose {
+		list = list.WithLogger(proxyprotocol.LoggerFunc(log.Printf))
+	}
+
+	srv := server{
+		headerTimeout: headerTimeout,
+		detectTimeout: detectTimeout,
+	}
+
+	log.Printf("Start listen on %s", rawList.Addr())
+	for {
+		conn, err := acceptretry.Accept(list, proxyprotocol.LoggerFunc(log.Printf))
+		if err != nil {
+			log.Fatal(err)
+		}
+		go srv.handleConn(conn)
+	}
+}
+
+// handleConn parse header of connection accepted by proxyprotocol.Listener
+// and answer with report
+func (srv server) handleConn(conn net.Conn) {
+	if err := conn.SetReadDeadline(time.Now().Add(srv.headerTimeout)); err != nil {
+		log.Printf("Set deadline error: %s", err)
+		conn.Close()
+		return
+	}
+
+	result := newReport(conn)
+	log.Printf("Connection from %s (trusted: %t, remote: %s, error: %q)",
+		result.PeerAddr, result.Trusted, result.RemoteAddr, result.Error)
+
+	peeked := sniff.NewConn(conn)
+	isHTTP := false
+	if result.Error == "" {
+		var err error
+		isHTTP, err = peeked.DetectHTTP(srv.detectTimeout)
+		if err != nil {
+			log.Printf("Detect HTTP error: %s", err)
+		}
+	}
+
+	if !isHTTP {
+		defer conn.Close()
+		if err := result.writeText(conn); err != nil {
+			log.Printf("Write report error: %s", err)
+		}
+		return
+	}
+
+	handler := http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		res.Header().Set("Content-Type", "application/json")
+		encoder := json.NewEncoder(res)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(result); err != nil {
+			log.Printf("Write report error: %s", err)
+		}
+	})
+	// Serve return after single connection accepted, connection served in
+	// background
+	http.Serve(&oneConnListener{conn: peeked}, handler) // nolint: errcheck
+}
@@ -0,0 +1,160 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/c0va23/go-proxyprotocol"
+	"github.com/c0va23/go-proxyprotocol/internal/sourcecheck"
+)
+
+func startServer(t *testing.T, trusted string) (string, func()) {
+	sourceChecker, err := sourcecheck.ParseCIDRs(trusted)
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := server{
+		headerTimeout: time.Second,
+		detectTimeout: 50 * time.Millisecond,
+	}
+
+	rawList, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	list := proxyprotocol.NewDefaultListener(rawList).
+		WithSourceChecker(sourceChecker).
+		WithLogger(proxyprotocol.LoggerFunc(t.Logf))
+
+	go func() {
+		for {
+			conn, err := list.Accept()
+			if err != nil {
+				return
+			}
+			go srv.handleConn(conn)
+		}
+	}()
+
+	return rawList.Addr().String(), func() { rawList.Close() }
+}
+
+func TestServer_handleConn(t *testing.T) {
+	header := &proxyprotocol.Header{
+		SrcAddr: &net.TCPAddr{IP: net.IPv4(192, 168, 1, 2), Port: 12345},
+		DstAddr: &net.TCPAddr{IP: net.IPv4(10, 0, 0, 2), Port: 8080},
+		TLVs:    []proxyprotocol.TLV{{Type: proxyprotocol.TLVTypeAuthority, Value: []byte("example.com")}},
+	}
+	headerBuf, err := header.FormatV2()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dial := func(t *testing.T, address string, payload []byte) net.Conn {
+		conn, err := net.Dial("tcp", address)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := conn.Write(append(append([]byte{}, headerBuf...), payload...)); err != nil {
+			t.Fatal(err)
+		}
+		return conn
+	}
+
+	t.Run("text", func(t *testing.T) {
+		address, stop := startServer(t, "")
+		defer stop()
+
+		conn := dial(t, address, nil)
+		defer conn.Close()
+
+		body, err := ioutil.ReadAll(conn)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, expected := range []string{
+			"Peer:           " + conn.LocalAddr().String(),
+			"Trusted:        true",
+			"Remote:         192.168.1.2:12345",
+			"Parse duration: ",
+			`TLV 0x02:       AUTHORITY (11 bytes) 6578616d706c652e636f6d "example.com"`,
+		} {
+			if !strings.Contains(string(body), expected) {
+				t.Errorf("Report %q not contain %q", body, expected)
+			}
+		}
+	})
+
+	t.Run("HTTP", func(t *testing.T) {
+		address, stop := startServer(t, "10.0.0.0/8")
+		defer stop()
+
+		conn := dial(t, address, []byte(fmt.Sprintf("GET / HTTP/1.0\r\nHost: %s\r\n\r\n", address)))
+		defer conn.Close()
+
+		res, err := http.ReadResponse(bufio.NewReader(conn), nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer res.Body.Close()
+
+		var result report
+		if err := json.NewDecoder(res.Body).Decode(&result); err != nil {
+			t.Fatal(err)
+		}
+		if result.Trusted || result.RemoteAddr != conn.LocalAddr().String() || result.PeerAddr != result.RemoteAddr {
+			t.Errorf("Unexpected untrusted report %+v", result)
+		}
+		if result.Header == nil || result.Header.SrcAddr != "192.168.1.2:12345" || len(result.Header.TLVs) != 1 {
+			t.Errorf("Unexpected header %+v", result.Header)
+		}
+	})
+
+	t.Run("without header", func(t *testing.T) {
+		address, stop := startServer(t, "")
+		defer stop()
+
+		res, err := http.Get("http://" + address + "/")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer res.Body.Close()
+
+		var result report
+		if err := json.NewDecoder(res.Body).Decode(&result); err != nil {
+			t.Fatal(err)
+		}
+		if result.Header != nil || result.Error != "" || result.RemoteAddr != result.PeerAddr {
+			t.Errorf("Unexpected report %+v", result)
+		}
+	})
+
+	t.Run("invalid header", func(t *testing.T) {
+		address, stop := startServer(t, "")
+		defer stop()
+
+		conn, err := net.Dial("tcp", address)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer conn.Close()
+		if _, err := conn.Write([]byte("PROXY TCP4 192.168.1.2\r\n")); err != nil {
+			t.Fatal(err)
+		}
+
+		body, err := ioutil.ReadAll(conn)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(string(body), "Error:          "+proxyprotocol.ErrInvalidAddressList.Error()) {
+			t.Errorf("Unexpected report %q", body)
+		}
+	})
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+var errQueueTimeout = errors.New("queue timeout")
+
+// serverState count active connections of server
+type serverState struct {
+	serverConfig
+	active int
+}
+
+func (server *serverState) available() bool {
+	return server.maxConn <= 0 || server.active < server.maxConn
+}
+
+// balancer select backend server with free connection slot. When all servers
+// busy, then connection wait in queue until slot released.
+type balancer struct {
+	mutex   sync.Mutex
+	balance string
+	servers []*serverState
+	next    int
+	// released closed and replaced on each release to wake up queue
+	released chan struct{}
+}
+
+func newBalancer(backend *backendConfig) *balancer {
+	servers := make([]*serverState, 0, len(backend.servers))
+	for _, server := range backend.servers {
+		servers = append(servers, &serverState{serverConfig: server})
+	}
+
+	return &balancer{
+		balance:  backend.balance,
+		servers:  servers,
+		released: make(chan struct{}),
+	}
+}
+
+// acquire select server and take its connection slot. Zero timeout mean
+// wait without limit.
+func (balancer *balancer) acquire(timeout time.Duration) (*serverState, error) {
+	var deadline <-chan time.Time
+	if timeout > 0 {
+		timer := time.NewTimer(timeout)
+		defer timer.Stop()
+		deadline = timer.C
+	}
+
+	for {
+		balancer.mutex.Lock()
+		server := balancer.selectServer()
+		if server != nil {
+			server.active++
+			balancer.mutex.Unlock()
+			return server, nil
+		}
+		released := balancer.released
+		balancer.mutex.Unlock()
+
+		select {
+		case <-released:
+		case <-deadline:
+			return nil, errQueueTimeout
+		}
+	}
+}
+
+// release return connection slot of server
+func (balancer *balancer) release(server *serverState) {
+	balancer.mutex.Lock()
+	defer balancer.mutex.Unlock()
+
+	server.active--
+	close(balancer.released)
+	balancer.released = make(chan struct{})
+}
+
+func (balancer *balancer) selectServer() *serverState {
+	if balancer.balance == balanceLeastConn {
+		return balancer.selectLeastConn()
+	}
+	return balancer.selectRoundRobin()
+}
+
+func (balancer *balancer) selectRoundRobin() *serverState {
+	for i := range balancer.servers {
+		index := (balancer.next + i) % len(balancer.servers)
+		if server := balancer.servers[index]; server.available() {
+			balancer.next = index + 1
+			return server
+		}
+	}
+	return nil
+}
+
+func (balancer *balancer) selectLeastConn() *serverState {
+	var selected *serverState
+	for _, server := range balancer.servers {
+		if server.available() && (selected == nil || server.active < selected.active) {
+			selected = server
+		}
+	}
+	return selected
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBalancer_acquire(t *testing.T) {
+	newTestBalancer := func(balance string) *balancer {
+		return newBalancer(&backendConfig{
+			proxyConfig: proxyConfig{balance: balance},
+			servers: []serverConfig{
+				{name: "server1", maxConn: 1},
+				{name: "server2", maxConn: 2},
+			},
+		})
+	}
+
+	acquireName := func(t *testing.T, balancer *balancer) string {
+		server, err := balancer.acquire(time.Millisecond)
+		if err != nil {
+			t.Fatalf("Unexpected error %s", err)
+		}
+		return server.name
+	}
+
+	t.Run("round robin skip busy servers", func(t *testing.T) {
+		balancer := newTestBalancer(balanceRoundRobin)
+
+		for _, expectedName := range []string{"server1", "server2", "server2"} {
+			if name := acquireName(t, balancer); name != expectedName {
+				t.Errorf("Unexpected server %s, expected %s", name, expectedName)
+			}
+		}
+
+		if _, err := balancer.acquire(time.Millisecond); err != errQueueTimeout {
+			t.Errorf("Unexpected error %v", err)
+		}
+	})
+
+	t.Run("least connections", func(t *testing.T) {
+		balancer := newTestBalancer(balanceLeastConn)
+
+		server, err := balancer.acquire(time.Millisecond)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if name := acquireName(t, balancer); name != "server2" {
+			t.Errorf("Unexpected server %s", name)
+		}
+
+		balancer.release(server)
+		if name := acquireName(t, balancer); name != "server1" {
+			t.Errorf("Unexpected server %s", name)
+		}
+	})
+
+	t.Run("queued connection wait release", func(t *testing.T) {
+		balancer := newTestBalancer(balanceRoundRobin)
+
+		var servers []*serverState
+		for i := 0; i < 3; i++ {
+			server, err := balancer.acquire(time.Millisecond)
+			if err != nil {
+				t.Fatal(err)
+			}
+			servers = append(servers, server)
+		}
+
+		go func() {
+			time.Sleep(10 * time.Millisecond)
+			balancer.release(servers[0])
+		}()
+
+		server, err := balancer.acquire(time.Second)
+		if err != nil || server != servers[0] {
+			t.Errorf("Unexpected server %v, error %v", server, err)
+		}
+	})
+}
@@ -0,0 +1,368 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/c0va23/go-proxyprotocol"
+)
+
+// Balance algorithms
+const (
+	balanceRoundRobin = "roundrobin"
+	balanceLeastConn  = "leastconn"
+)
+
+// config is parsed subset of haproxy.conf
+type config struct {
+	maxConn   int
+	frontends []*frontendConfig
+	backends  map[string]*backendConfig
+}
+
+// proxyConfig contain settings inherited from defaults section
+type proxyConfig struct {
+	mode           string
+	balance        string
+	connectTimeout time.Duration
+	clientTimeout  time.Duration
+	serverTimeout  time.Duration
+	queueTimeout   time.Duration
+}
+
+type frontendConfig struct {
+	proxyConfig
+	name           string
+	binds          []string
+	defaultBackend string
+}
+
+type backendConfig struct {
+	proxyConfig
+	name    string
+	servers []serverConfig
+}
+
+type serverConfig struct {
+	name    string
+	address string
+	maxConn int
+	// version of sent proxyprotocol header. Zero when header not sent.
+	version byte
+}
+
+// configParser keep state of parsing section
+type configParser struct {
+	config    *config
+	defaults  proxyConfig
+	section   string
+	frontend  *frontendConfig
+	backend   *backendConfig
+	lineNum   int
+	arguments []string
+}
+
+// parseConfig parse global, defaults, frontend and backend sections.
+// Each defaults section applied to following frontends and backends.
+func parseConfig(reader io.Reader) (*config, error) {
+	parser := configParser{
+		config: &config{
+			backends: make(map[string]*backendConfig),
+		},
+		defaults: proxyConfig{
+			mode:    "tcp",
+			balance: balanceRoundRobin,
+		},
+	}
+
+	scanner := bufio.NewScanner(reader)
+	for scanner.Scan() {
+		parser.lineNum++
+
+		line := scanner.Text()
+		if commentPos := strings.IndexByte(line, '#'); commentPos >= 0 {
+			line = line[:commentPos]
+		}
+
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+
+		parser.arguments = fields[1:]
+		if err := parser.parseKeyword(fields[0]); err != nil {
+			return nil, fmt.Errorf("line %d: %s", parser.lineNum, err)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	if err := parser.config.validate(); err != nil {
+		return nil, err
+	}
+
+	return parser.config, nil
+}
+
+func (parser *configParser) parseKeyword(keyword string) error {
+	switch keyword {
+	case "global", "defaults":
+		parser.section = keyword
+		return parser.expectArguments(0)
+	case "frontend":
+		if err := parser.expectArguments(1); err != nil {
+			return err
+		}
+		parser.section = keyword
+		parser.frontend = &frontendConfig{
+			proxyConfig: parser.defaults,
+			name:        parser.arguments[0],
+		}
+		parser.config.frontends = append(parser.config.frontends, parser.frontend)
+		return nil
+	case "backend":
+		if err := parser.expectArguments(1); err != nil {
+			return err
+		}
+		name := parser.arguments[0]
+		if _, found := parser.config.backends[name]; found {
+			return fmt.Errorf("duplicate backend %s", name)
+		}
+		parser.section = keyword
+		parser.backend = &backendConfig{
+			proxyConfig: parser.defaults,
+			name:        name,
+		}
+		parser.config.backends[name] = parser.backend
+		return nil
+	}
+
+	switch parser.section {
+	case "global":
+		return parser.parseGlobal(keyword)
+	case "defaults":
+		return parser.parseProxy(&parser.defaults, keyword)
+	case "frontend":
+		return parser.parseFrontend(keyword)
+	case "backend":
+		return parser.parseBackend(keyword)
+	default:
+		return fmt.Errorf("keyword %s outside of section", keyword)
+	}
+}
+
+func (parser *configParser) parseGlobal(keyword string) error {
+	switch keyword {
+	case "daemon":
+		return parser.expectArguments(0)
+	case "maxconn":
+		return parser.parseInt(&parser.config.maxConn)
+	default:
+		return fmt.Errorf("unsupported global keyword %s", keyword)
+	}
+}
+
+func (parser *configParser) parseProxy(proxy *proxyConfig, keyword string) error {
+	switch keyword {
+	case "mode":
+		if err := parser.expectArguments(1); err != nil {
+			return err
+		}
+		if parser.arguments[0] != "tcp" {
+			return fmt.Errorf("unsupported mode %s", parser.arguments[0])
+		}
+		proxy.mode = parser.arguments[0]
+		return nil
+	case "balance":
+		if err := parser.expectArguments(1); err != nil {
+			return err
+		}
+		switch parser.arguments[0] {
+		case balanceRoundRobin, balanceLeastConn:
+			proxy.balance = parser.arguments[0]
+			return nil
+		default:
+			return fmt.Errorf("unsupported balance %s", parser.arguments[0])
+		}
+	case "timeout":
+		return parser.parseTimeout(proxy)
+	default:
+		return fmt.Errorf("unsupported keyword %s", keyword)
+	}
+}
+
+func (parser *configParser) parseTimeout(proxy *proxyConfig) error {
+	if err := parser.expectArguments(2); err != nil {
+		return err
+	}
+
+	timeout, err := parseDuration(parser.arguments[1])
+	if err != nil {
+		return err
+	}
+
+	switch parser.arguments[0] {
+	case "connect":
+		proxy.connectTimeout = timeout
+	case "client":
+		proxy.clientTimeout = timeout
+	case "server":
+		proxy.serverTimeout = timeout
+	case "queue":
+		proxy.queueTimeout = timeout
+	default:
+		return fmt.Errorf("unsupported timeout %s", parser.arguments[0])
+	}
+	return nil
+}
+
+func (parser *configParser) parseFrontend(keyword string) error {
+	switch keyword {
+	case "bind":
+		if err := parser.expectArguments(1); err != nil {
+			return err
+		}
+		address, err := parseAddress(parser.arguments[0])
+		if err != nil {
+			return err
+		}
+		parser.frontend.binds = append(parser.frontend.binds, address)
+		return nil
+	case "default_backend":
+		if err := parser.expectArguments(1); err != nil {
+			return err
+		}
+		parser.frontend.defaultBackend = parser.arguments[0]
+		return nil
+	default:
+		return parser.parseProxy(&parser.frontend.proxyConfig, keyword)
+	}
+}
+
+func (parser *configParser) parseBackend(keyword string) error {
+	if keyword != "server" {
+		return parser.parseProxy(&parser.backend.proxyConfig, keyword)
+	}
+
+	if len(parser.arguments) < 2 {
+		return fmt.Errorf("server require name and address")
+	}
+
+	address, err := parseAddress(parser.arguments[1])
+	if err != nil {
+		return err
+	}
+
+	server := serverConfig{
+		name:    parser.arguments[0],
+		address: address,
+	}
+
+	options := parser.arguments[2:]
+	for len(options) > 0 {
+		switch options[0] {
+		case "send-proxy":
+			server.version = proxyprotocol.Version1
+		case "send-proxy-v2":
+			server.version = proxyprotocol.Version2
+		case "maxconn":
+			if len(options) < 2 {
+				return fmt.Errorf("maxconn require value")
+			}
+			if server.maxConn, err = strconv.Atoi(options[1]); err != nil {
+				return err
+			}
+			options = options[1:]
+		default:
+			return fmt.Errorf("unsupported server option %s", options[0])
+		}
+		options = options[1:]
+	}
+
+	parser.backend.servers = append(parser.backend.servers, server)
+	return nil
+}
+
+func (parser *configParser) expectArguments(count int) error {
+	if len(parser.arguments) != count {
+		return fmt.Errorf("expected %d arguments, got %d", count, len(parser.arguments))
+	}
+	return nil
+}
+
+func (parser *configParser) parseInt(value *int) error {
+	if err := parser.expectArguments(1); err != nil {
+		return err
+	}
+
+	var err error
+	*value, err = strconv.Atoi(parser.arguments[0])
+	return err
+}
+
+func (config *config) validate() error {
+	if len(config.frontends) == 0 {
+		return fmt.Errorf("no frontends")
+	}
+
+	for _, frontend := range config.frontends {
+		if len(frontend.binds) == 0 {
+			return fmt.Errorf("frontend %s: no bind", frontend.name)
+		}
+		if _, found := config.backends[frontend.defaultBackend]; !found {
+			return fmt.Errorf("frontend %s: unknown backend %q", frontend.name, frontend.defaultBackend)
+		}
+	}
+
+	for _, backend := range config.backends {
+		if len(backend.servers) == 0 {
+			return fmt.Errorf("backend %s: no servers", backend.name)
+		}
+	}
+
+	return nil
+}
+
+// parseAddress convert HAProxy address into Go address. Port separated by
+// last colon, so IPv6 address can be written without brackets. Wildcard host
+// "*" mean all addresses.
+func parseAddress(address string) (string, error) {
+	portPos := strings.LastIndexByte(address, ':')
+	if portPos < 0 {
+		return "", fmt.Errorf("address %s without port", address)
+	}
+
+	host, port := address[:portPos], address[portPos+1:]
+	if _, err := strconv.ParseUint(port, 10, 16); err != nil {
+		return "", fmt.Errorf("invalid port in address %s", address)
+	}
+
+	host = strings.TrimSuffix(strings.TrimPrefix(host, "["), "]")
+	if host == "*" {
+		host = ""
+	}
+
+	return net.JoinHostPort(host, port), nil
+}
+
+// parseDuration parse HAProxy time. Number without unit is milliseconds.
+func parseDuration(value string) (time.Duration, error) {
+	if value != "" && strings.IndexFunc(value, func(char rune) bool {
+		return char < '0' || char > '9'
+	}) < 0 {
+		value += "ms"
+	}
+
+	if strings.HasSuffix(value, "d") {
+		days, err := strconv.Atoi(strings.TrimSuffix(value, "d"))
+		return time.Duration(days) * 24 * time.Hour, err
+	}
+
+	return time.ParseDuration(value)
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/c0va23/go-proxyprotocol"
+)
+
+func TestParseConfig(t *testing.T) {
+	t.Run("example config", func(t *testing.T) {
+		configFile, err := os.Open("../../example/simplehttp/haproxy.conf")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer configFile.Close()
+
+		config, err := parseConfig(configFile)
+		if err != nil {
+			t.Fatalf("Unexpected error %s", err)
+		}
+
+		if config.maxConn != 256 {
+			t.Errorf("Unexpected maxconn %d", config.maxConn)
+		}
+
+		if len(config.frontends) != 6 || len(config.backends) != 6 {
+			t.Fatalf("Unexpected frontends %d, backends %d", len(config.frontends), len(config.backends))
+		}
+
+		frontend := config.frontends[5]
+		if frontend.name != "binary6" || frontend.binds[0] != "[::]:1062" ||
+			frontend.defaultBackend != "binary6" || frontend.clientTimeout != 50*time.Second {
+			t.Errorf("Unexpected frontend %+v", frontend)
+		}
+
+		expectedVersions := map[string]byte{
+			"raw4":    0,
+			"text4":   proxyprotocol.Version1,
+			"binary4": proxyprotocol.Version2,
+		}
+		for name, version := range expectedVersions {
+			server := config.backends[name].servers[0]
+			if server.version != version || server.maxConn != 32 || server.address != "127.0.0.1:8080" {
+				t.Errorf("Unexpected server %+v", server)
+			}
+		}
+
+		backend := config.backends["text6"]
+		if backend.connectTimeout != 5*time.Second || backend.serverTimeout != 50*time.Second ||
+			backend.balance != balanceRoundRobin || backend.servers[0].address != "[::1]:8080" {
+			t.Errorf("Unexpected backend %+v", backend)
+		}
+	})
+
+	invalidConfigs := map[string]string{
+		"unknown keyword":  "global\n    nbproc 2\n",
+		"http mode":        "defaults\n    mode http\n",
+		"unknown backend":  "frontend web\n    bind *:80\n    default_backend app\n",
+		"without servers":  "frontend web\n    bind *:80\n    default_backend app\nbackend app\n",
+		"invalid address":  "backend app\n    server app1 127.0.0.1\n",
+		"unknown option":   "backend app\n    server app1 127.0.0.1:80 check\n",
+		"without sections": "maxconn 10\n",
+	}
+	for name, configData := range invalidConfigs {
+		t.Run(name, func(t *testing.T) {
+			if _, err := parseConfig(strings.NewReader(configData)); err == nil {
+				t.Error("Expected error")
+			}
+		})
+	}
+}
+
+func TestParseDuration(t *testing.T) {
+	durations := map[string]time.Duration{
+		"5000":   5 * time.Second,
+		"5000ms": 5 * time.Second,
+		"50s":    50 * time.Second,
+		"2m":     2 * time.Minute,
+		"1d":     24 * time.Hour,
+	}
+	for value, expectedDuration := range durations {
+		if duration, err := parseDuration(value); err != nil || duration != expectedDuration {
+			t.Errorf("Unexpected duration %s for %s, error %v", duration, value, err)
+		}
+	}
+}
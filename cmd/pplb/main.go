@@ -0,0 +1,178 @@
+// Command pplb is L4 load balancer which understand subset of haproxy.conf:
+// global maxconn, defaults with mode tcp and timeouts, frontend bind and
+// default_backend, backend balance and server lines with maxconn, send-proxy
+// and send-proxy-v2 options.
+//
+// Usage:
+//
+//	pplb -config example/simplehttp/haproxy.conf
+package main
+
+import (
+	"flag"
+	"log"
+	"net"
+	"os"
+	"time"
+
+	"github.com/c0va23/go-proxyprotocol"
+	"github.com/c0va23/go-proxyprotocol/internal/acceptretry"
+)
+
+func main() {
+	var (
+		configPath string
+		verbose    bool
+	)
+	flag.StringVar(&configPath, "config", "haproxy.conf", "HAProxy config path")
+	flag.BoolVar(&verbose, "verbose", false, "Log each connection")
+	flag.Parse()
+
+	configFile, err := os.Open(configPath)
+	if err != nil {
+		log.Fatal(err)
+	}
+	config, err := parseConfig(configFile)
+	configFile.Close()
+	if err != nil {
+		log.Fatalf("Parse config %s error: %s", configPath, err)
+	}
+
+	var connSlots chan struct{}
+	if config.maxConn > 0 {
+		connSlots = make(chan struct{}, config.maxConn)
+	}
+
+	balancers := make(map[string]*balancer, len(config.backends))
+	for name, backend := range config.backends {
+		balancers[name] = newBalancer(backend)
+	}
+
+	errs := make(chan error)
+	for _, frontendConfig := range config.frontends {
+		backendConfig := config.backends[frontendConfig.defaultBackend]
+		for _, bind := range frontendConfig.binds {
+			listener, err := net.Listen("tcp", bind)
+			if err != nil {
+				log.Fatal(err)
+			}
+			log.Printf("Frontend %s listen on %s", frontendConfig.name, listener.Addr())
+
+			frontend := &frontend{
+				listener:  listener,
+				frontend:  frontendConfig,
+				backend:   backendConfig,
+				balancer:  balancers[backendConfig.name],
+				connSlots: connSlots,
+				verbose:   verbose,
+			}
+			go func() {
+				errs <- frontend.serve()
+			}()
+		}
+	}
+
+	log.Fatal(<-errs)
+}
+
+// frontend accept connections and relay them to backend servers
+type frontend struct {
+	listener  net.Listener
+	frontend  *frontendConfig
+	backend   *backendConfig
+	balancer  *balancer
+	connSlots chan struct{}
+	verbose   bool
+}
+
+// serve accept connections while global maxconn not reached. Temporary
+// Accept errors retried, permanent error returned.
+func (frontend *frontend) serve() error {
+	for {
+		if frontend.connSlots != nil {
+			frontend.connSlots <- struct{}{}
+		}
+
+		conn, err := acceptretry.Accept(frontend.listener, proxyprotocol.LoggerFunc(log.Printf))
+		if err != nil {
+			return err
+		}
+
+		go func() {
+			frontend.handleConn(conn)
+			if frontend.connSlots != nil {
+				<-frontend.connSlots
+			}
+		}()
+	}
+}
+
+func (frontend *frontend) handleConn(conn net.Conn) {
+	defer conn.Close()
+
+	queueTimeout := frontend.backend.queueTimeout
+	if queueTimeout == 0 {
+		queueTimeout = frontend.backend.connectTimeout
+	}
+
+	server, err := frontend.balancer.acquire(queueTimeout)
+	if err != nil {
+		log.Printf("Frontend %s connection from %s error: %s", frontend.frontend.name, conn.RemoteAddr(), err)
+		return
+	}
+	defer frontend.balancer.release(server)
+
+	if frontend.verbose {
+		log.Printf("Frontend %s relay %s to %s/%s", frontend.frontend.name, conn.RemoteAddr(), frontend.backend.name, server.name)
+	}
+
+	serverConn, err := dialServer(conn, server.serverConfig, frontend.backend.connectTimeout)
+	if err != nil {
+		log.Printf("Backend %s server %s error: %s", frontend.backend.name, server.name, err)
+		return
+	}
+	defer serverConn.Close()
+
+	err = proxyprotocol.Pipe(
+		idleTimeoutConn{Conn: conn, timeout: frontend.frontend.clientTimeout},
+		idleTimeoutConn{Conn: serverConn, timeout: frontend.backend.serverTimeout},
+	)
+	if err != nil && frontend.verbose {
+		log.Printf("Frontend %s connection from %s error: %s", frontend.frontend.name, conn.RemoteAddr(), err)
+	}
+}
+
+// dialServer connect to server and send header when server configured with
+// send-proxy or send-proxy-v2
+func dialServer(conn net.Conn, server serverConfig, connectTimeout time.Duration) (net.Conn, error) {
+	if server.version == 0 {
+		return net.DialTimeout("tcp", server.address, connectTimeout)
+	}
+
+	dialer := proxyprotocol.NewDialer(server.version).WithConn(conn)
+	dialer.Timeout = connectTimeout
+	return dialer.Dial("tcp", server.address)
+}
+
+// idleTimeoutConn close connection which not receive data in timeout
+type idleTimeoutConn struct {
+	net.Conn
+	timeout time.Duration
+}
+
+func (conn idleTimeoutConn) Read(buf []byte) (int, error) {
+	if conn.timeout > 0 {
+		if err := conn.SetReadDeadline(time.Now().Add(conn.timeout)); err != nil {
+			return 0, err
+		}
+	}
+	return conn.Conn.Read(buf)
+}
+
+// CloseWrite close write side of TCP connection
+func (conn idleTimeoutConn) CloseWrite() error {
+	if tcpConn, ok := conn.Conn.(*net.TCPConn); ok {
+		return tcpConn.CloseWrite()
+	}
+	return conn.Conn.Close()
+}
@@ -0,0 +1,70 @@
+// Command pprelay accept TCP connections and relay them to backend with
+// proxyprotocol header, like HAProxy with send-proxy or send-proxy-v2 option.
+//
+// Usage:
+//
+//	pprelay -bind :1042 -backend 127.0.0.1:8080 -version 2
+package main
+
+import (
+	"flag"
+	"log"
+	"net"
+
+	"github.com/c0va23/go-proxyprotocol"
+)
+
+func main() {
+	var (
+		addr     string
+		backend  string
+		version  uint
+		align    int
+		checksum bool
+		verbose  bool
+	)
+	flag.StringVar(&addr, "bind", ":1042", "Bind address")
+	flag.StringVar(&backend, "backend", "127.0.0.1:8080", "Backend address")
+	flag.UintVar(&version, "version", 2, "Header version (1 or 2)")
+	flag.IntVar(&align, "align", 0, "Align v2 header with NOOP TLV")
+	flag.BoolVar(&checksum, "checksum", false, "Add CRC32C TLV to v2 header")
+	flag.BoolVar(&verbose, "verbose", false, "Log each connection")
+	flag.Parse()
+
+	if version != uint(proxyprotocol.Version1) && version != uint(proxyprotocol.Version2) {
+		log.Fatalf("Unknown header version %d", version)
+	}
+
+	dialer := proxyprotocol.NewDialer(byte(version))
+	dialer.BinaryFormatOptions = proxyprotocol.BinaryFormatOptions{
+		Align:    align,
+		Checksum: checksum,
+	}
+
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if verbose {
+		listener = loggingListener{Listener: listener}
+	}
+
+	log.Printf("Relay %s to %s with header v%d", listener.Addr(), backend, version)
+	if err := proxyprotocol.Relay(listener, dialer, "tcp", backend, proxyprotocol.LoggerFunc(log.Printf)); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// loggingListener log accepted connections
+type loggingListener struct {
+	net.Listener
+}
+
+func (listener loggingListener) Accept() (net.Conn, error) {
+	conn, err := listener.Listener.Accept()
+	if err == nil {
+		log.Printf("Accept connection from %s", conn.RemoteAddr())
+	}
+	return conn, err
+}
@@ -0,0 +1,96 @@
+// Command ppsend connect to server, send proxyprotocol header built from
+// flags and then pipe stdin to connection and connection to stdout, like
+// netcat.
+//
+// Usage:
+//
+//	ppsend -src 192.168.1.2:12345 -dst 10.0.0.2:8080 localhost:8080
+//	printf 'GET / HTTP/1.0\r\n\r\n' | ppsend -version 1 -malform truncate localhost:8080
+package main
+
+import (
+	"encoding/hex"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"os"
+	"time"
+
+	"github.com/c0va23/go-proxyprotocol/internal/headerflags"
+)
+
+func main() {
+	var (
+		headerFlags headerflags.Flags
+		timeout     time.Duration
+		noStdin     bool
+		verbose     bool
+	)
+	headerFlags.Register(flag.CommandLine)
+	flag.DurationVar(&timeout, "timeout", 5*time.Second, "Connect timeout")
+	flag.BoolVar(&noStdin, "n", false, "Do not read stdin, only send header")
+	flag.BoolVar(&verbose, "verbose", false, "Print sent header to stderr")
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s [flags] HOST:PORT\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if flag.NArg() != 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	headerBuf, err := headerFlags.Format()
+	if err != nil {
+		log.Fatalf("Build header error: %s", err)
+	}
+
+	conn, err := net.DialTimeout("tcp", flag.Arg(0), timeout)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer conn.Close()
+
+	if verbose {
+		log.Printf("Connected to %s from %s", conn.RemoteAddr(), conn.LocalAddr())
+		log.Printf("Send header (%d bytes):\n%s", len(headerBuf), hex.Dump(headerBuf))
+	}
+
+	if _, err := conn.Write(headerBuf); err != nil {
+		log.Fatalf("Send header error: %s", err)
+	}
+
+	if err := pipe(conn, noStdin); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// pipe copy stdin to connection and connection to stdout. Write side of
+// connection closed on stdin EOF. Return when server close connection.
+func pipe(conn net.Conn, noStdin bool) error {
+	stdinErrs := make(chan error, 1)
+	go func() {
+		var err error
+		if !noStdin {
+			_, err = io.Copy(conn, os.Stdin)
+		}
+		if tcpConn, ok := conn.(*net.TCPConn); ok {
+			tcpConn.CloseWrite() // nolint: errcheck
+		}
+		stdinErrs <- err
+	}()
+
+	if _, err := io.Copy(os.Stdout, conn); err != nil {
+		return err
+	}
+
+	select {
+	case err := <-stdinErrs:
+		return err
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"errors"
+	"net"
+	"sync"
+)
+
+var errListenerClosed = errors.New("listener closed")
+
+// connListener is net.Listener which accept pushed connections
+type connListener struct {
+	addr  net.Addr
+	conns chan net.Conn
+	done  chan struct{}
+	once  sync.Once
+}
+
+func newConnListener(addr net.Addr) *connListener {
+	return &connListener{
+		addr:  addr,
+		conns: make(chan net.Conn),
+		done:  make(chan struct{}),
+	}
+}
+
+func (list *connListener) push(conn net.Conn) {
+	select {
+	case list.conns <- conn:
+	case <-list.done:
+		conn.Close()
+	}
+}
+
+func (list *connListener) Accept() (net.Conn, error) {
+	select {
+	case conn := <-list.conns:
+		return conn, nil
+	case <-list.done:
+		return nil, errListenerClosed
+	}
+}
+
+func (list *connListener) Close() error {
+	list.once.Do(func() {
+		close(list.done)
+	})
+	return nil
+}
+
+func (list *connListener) Addr() net.Addr {
+	return list.addr
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"net"
+	"net/http"
+	"strings"
+)
+
+// setForwardedHeaders set X-Forwarded-For, X-Real-IP and Forwarded headers.
+// RemoteAddr and LocalAddr of request connection is addresses from
+// proxyprotocol header. When forwarding headers not trusted, then client
+// values replaced.
+//
+// X-Forwarded-For filled by httputil.ReverseProxy from RemoteAddr.
+func setForwardedHeaders(req *http.Request, trustForwarded bool) {
+	if !trustForwarded {
+		req.Header.Del("X-Forwarded-For")
+		req.Header.Del("X-Real-IP")
+		req.Header.Del("Forwarded")
+	}
+
+	clientIP := addrIP(req.RemoteAddr)
+	if clientIP == nil {
+		return
+	}
+
+	if req.Header.Get("X-Real-IP") == "" {
+		req.Header.Set("X-Real-IP", clientIP.String())
+	}
+
+	forwarded := []string{"for=" + forwardedNode(clientIP)}
+	if localAddr, ok := req.Context().Value(http.LocalAddrContextKey).(net.Addr); ok {
+		if localIP := addrIP(localAddr.String()); localIP != nil {
+			forwarded = append(forwarded, "by="+forwardedNode(localIP))
+		}
+	}
+	if req.Host != "" {
+		forwarded = append(forwarded, "host="+forwardedValue(req.Host))
+	}
+	proto := "http"
+	if req.TLS != nil {
+		proto = "https"
+	}
+	forwarded = append(forwarded, "proto="+proto)
+
+	forwardedElement := strings.Join(forwarded, ";")
+	if prior := req.Header.Get("Forwarded"); prior != "" {
+		forwardedElement = prior + ", " + forwardedElement
+	}
+	req.Header.Set("Forwarded", forwardedElement)
+}
+
+func addrIP(addr string) net.IP {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return nil
+	}
+	return net.ParseIP(host)
+}
+
+// forwardedNode format IP as RFC 7239 node. IPv6 node quoted.
+func forwardedNode(ip net.IP) string {
+	if ip.To4() != nil {
+		return ip.String()
+	}
+	return `"[` + ip.String() + `]"`
+}
+
+// forwardedValue quote value when it is not token
+func forwardedValue(value string) string {
+	for _, char := range value {
+		if !isTokenChar(char) {
+			return `"` + strings.Replace(strings.Replace(value, `\`, `\\`, -1), `"`, `\"`, -1) + `"`
+		}
+	}
+	return value
+}
+
+func isTokenChar(char rune) bool {
+	switch {
+	case char >= 'a' && char <= 'z', char >= 'A' && char <= 'Z', char >= '0' && char <= '9':
+		return true
+	}
+	return strings.ContainsRune("!#$%&'*+-.^_`|~", char)
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"testing"
+)
+
+func TestSetForwardedHeaders(t *testing.T) {
+	newRequest := func(remoteAddr string, localAddr net.Addr) *http.Request {
+		req, err := http.NewRequest(http.MethodGet, "http://example.com:8080/", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.RemoteAddr = remoteAddr
+		req.Header.Set("X-Forwarded-For", "10.0.0.1")
+		req.Header.Set("X-Real-IP", "10.0.0.1")
+		req.Header.Set("Forwarded", "for=10.0.0.1")
+		return req.WithContext(context.WithValue(req.Context(), http.LocalAddrContextKey, localAddr))
+	}
+
+	t.Run("when forwarding headers not trusted", func(t *testing.T) {
+		req := newRequest("192.168.1.2:12345", &net.TCPAddr{IP: net.IPv4(10, 0, 0, 2), Port: 8080})
+		setForwardedHeaders(req, false)
+
+		if xff := req.Header.Get("X-Forwarded-For"); xff != "" {
+			t.Errorf("Unexpected X-Forwarded-For %s", xff)
+		}
+		if realIP := req.Header.Get("X-Real-IP"); realIP != "192.168.1.2" {
+			t.Errorf("Unexpected X-Real-IP %s", realIP)
+		}
+		expectedForwarded := `for=192.168.1.2;by=10.0.0.2;host="example.com:8080";proto=http`
+		if forwarded := req.Header.Get("Forwarded"); forwarded != expectedForwarded {
+			t.Errorf("Unexpected Forwarded %s", forwarded)
+		}
+	})
+
+	t.Run("when forwarding headers trusted", func(t *testing.T) {
+		req := newRequest("[::1]:12345", &net.TCPAddr{IP: net.ParseIP("::2"), Port: 8080})
+		setForwardedHeaders(req, true)
+
+		if xff := req.Header.Get("X-Forwarded-For"); xff != "10.0.0.1" {
+			t.Errorf("Unexpected X-Forwarded-For %s", xff)
+		}
+		if realIP := req.Header.Get("X-Real-IP"); realIP != "10.0.0.1" {
+			t.Errorf("Unexpected X-Real-IP %s", realIP)
+		}
+		expectedForwarded := `for=10.0.0.1, for="[::1]";by="[::2]";host="example.com:8080";proto=http`
+		if forwarded := req.Header.Get("Forwarded"); forwarded != expectedForwarded {
+			t.Errorf("Unexpected Forwarded %s", forwarded)
+		}
+	})
+}
@@ -0,0 +1,172 @@
+// Command ppsidecar terminate proxyprotocol for legacy applications.
+//
+// HTTP requests reverse-proxied to application with X-Forwarded-For,
+// X-Real-IP and Forwarded (RFC 7239) headers built from proxyprotocol header
+// addresses. Other traffic forwarded to application as raw TCP.
+//
+// Header trusted only from peers in -trusted networks (loopback by default).
+// For other peers raw peer address used.
+//
+// Usage:
+//
+//	ppsidecar -bind :1042 -backend 127.0.0.1:8080 -trusted 10.0.0.0/8
+package main
+
+import (
+	"flag"
+	"log"
+	"net"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"time"
+
+	"github.com/c0va23/go-proxyprotocol"
+	"github.com/c0va23/go-proxyprotocol/internal/acceptretry"
+	"github.com/c0va23/go-proxyprotocol/internal/sniff"
+	"github.com/c0va23/go-proxyprotocol/internal/sourcecheck"
+)
+
+func main() {
+	var (
+		addr           string
+		backend        string
+		tcpBackend     string
+		headerTimeout  time.Duration
+		detectTimeout  time.Duration
+		trustForwarded bool
+		trusted        string
+		verbose        bool
+	)
+	flag.StringVar(&addr, "bind", ":1042", "Bind address")
+	flag.StringVar(&backend, "backend", "127.0.0.1:8080", "Application address")
+	flag.StringVar(&tcpBackend, "tcp-backend", "", "Application address for non-HTTP traffic (default -backend)")
+	flag.DurationVar(&headerTimeout, "header-timeout", 5*time.Second, "Timeout of waiting proxyprotocol header")
+	flag.DurationVar(&detectTimeout, "detect-timeout", time.Second, "Timeout of waiting first client bytes after header")
+	flag.BoolVar(&trustForwarded, "trust-forwarded", false, "Keep forwarding headers sent by client")
+	flag.StringVar(&trusted, "trusted", "127.0.0.0/8,::1/128", "Comma-separated CIDRs of load balancers allowed to send header (empty trust all)")
+	flag.BoolVar(&verbose, "verbose", false, "Log proxyprotocol parsing")
+	flag.Parse()
+
+	if tcpBackend == "" {
+		tcpBackend = backend
+	}
+
+	sourceChecker, err := sourcecheck.ParseCIDRs(trusted)
+	if err != nil {
+		log.Fatalf("Invalid trusted list: %s", err)
+	}
+
+	rawList, err := net.Listen("tcp", addr)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	list := proxyprotocol.NewDefaultListener(rawList).WithSourceChecker(sourceChecker)
+	if verbose {
+		list = list.WithLogger(proxyprotocol.LoggerFunc(log.Printf))
+	}
+
+	httpList := newConnListener(rawList.Addr())
+	server := &http.Server{
+		Handler: newReverseProxy(&url.URL{Scheme: "http", Host: backend}, trustForwarded),
+	}
+	go func() {
+		if err := server.Serve(httpList); err != nil && err != errListenerClosed {
+			log.Fatal(err)
+		}
+	}()
+
+	log.Printf("Start listen on %s", rawList.Addr())
+	for {
+		conn, err := acceptretry.Accept(list, proxyprotocol.LoggerFunc(log.Printf))
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		go handleConn(conn, httpList, tcpBackend, headerTimeout, detectTimeout)
+	}
+}
+
+func newReverseProxy(backendURL *url.URL, trustForwarded bool) *httputil.ReverseProxy {
+	proxy := httputil.NewSingleHostReverseProxy(backendURL)
+	director := proxy.Director
+	proxy.Director = func(req *http.Request) {
+		director(req)
+		setForwardedHeaders(req, trustForwarded)
+	}
+	return proxy
+}
+
+// handleConn read proxyprotocol header and detect HTTP traffic by first
+// client bytes after it. HTTP connections passed to HTTP server, other
+// connections forwarded to TCP backend.
+//
+// Client which send nothing in headerTimeout wait for server to speak first,
+// so its raw connection forwarded to TCP backend. Bytes of incomplete header
+// lost in this case.
+func handleConn(conn net.Conn, httpList *connListener, tcpBackend string, headerTimeout, detectTimeout time.Duration) {
+	if err := conn.SetReadDeadline(time.Now().Add(headerTimeout)); err != nil {
+		log.Printf("Connection from %s error: %s", conn.RemoteAddr(), err)
+		conn.Close()
+		return
+	}
+
+	connHeader, _ := proxyprotocol.HeaderFromConn(conn)
+	if netErr, ok := connHeader.Err.(net.Error); ok && netErr.Timeout() {
+		if err := forwardRawTCP(conn, tcpBackend); err != nil {
+			log.Printf("Forward connection from %s error: %s", connHeader.PeerAddr, err)
+		}
+		return
+	}
+	if connHeader.Err != nil {
+		log.Printf("Connection from %s header error: %s", connHeader.PeerAddr, connHeader.Err)
+		conn.Close()
+		return
+	}
+
+	peeked := sniff.NewConn(conn)
+	isHTTP, err := peeked.DetectHTTP(detectTimeout)
+	if err != nil {
+		log.Printf("Connection from %s error: %s", conn.RemoteAddr(), err)
+		conn.Close()
+		return
+	}
+
+	if isHTTP {
+		httpList.push(peeked)
+		return
+	}
+
+	if err := forwardTCP(peeked, tcpBackend); err != nil {
+		log.Printf("Forward connection from %s error: %s", conn.RemoteAddr(), err)
+	}
+}
+
+// forwardRawTCP forward connection under proxyprotocol.Conn, which header
+// read failed
+func forwardRawTCP(conn net.Conn, tcpBackend string) error {
+	rawConn := conn
+	if ppConn, ok := conn.(*proxyprotocol.Conn); ok {
+		rawConn = ppConn.Conn
+	}
+
+	if err := rawConn.SetReadDeadline(time.Time{}); err != nil {
+		rawConn.Close()
+		return err
+	}
+
+	return forwardTCP(rawConn, tcpBackend)
+}
+
+func forwardTCP(conn net.Conn, tcpBackend string) error {
+	defer conn.Close()
+
+	backendConn, err := net.DialTimeout("tcp", tcpBackend, 5*time.Second)
+	if err != nil {
+		return err
+	}
+	defer backendConn.Close()
+
+	return proxyprotocol.Pipe(conn, backendConn)
+}
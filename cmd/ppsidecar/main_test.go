@@ -0,0 +1,111 @@
+package main
+
+import (
+	"bufio"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/c0va23/go-proxyprotocol"
+)
+
+// startBannerBackend send banner and then echo client data
+func startBannerBackend(t *testing.T) net.Listener {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				if _, err := conn.Write([]byte("HELLO\n")); err != nil {
+					return
+				}
+				io.Copy(conn, conn) // nolint: errcheck
+			}()
+		}
+	}()
+
+	return listener
+}
+
+func TestHandleConn(t *testing.T) {
+	backendListener := startBannerBackend(t)
+	defer backendListener.Close()
+
+	rawList, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rawList.Close()
+
+	list := proxyprotocol.NewDefaultListener(rawList).WithLogger(proxyprotocol.LoggerFunc(t.Logf))
+	httpList := newConnListener(rawList.Addr())
+	defer httpList.Close()
+
+	go func() {
+		for {
+			conn, err := list.Accept()
+			if err != nil {
+				return
+			}
+			go handleConn(conn, httpList, backendListener.Addr().String(), 300*time.Millisecond, 50*time.Millisecond)
+		}
+	}()
+
+	readLine := func(t *testing.T, reader *bufio.Reader) string {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			t.Fatalf("Read error: %s", err)
+		}
+		return line
+	}
+
+	t.Run("when header sent after detect timeout", func(t *testing.T) {
+		conn, err := net.Dial("tcp", rawList.Addr().String())
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer conn.Close()
+
+		time.Sleep(100 * time.Millisecond)
+		if _, err := conn.Write([]byte("PROXY TCP4 192.168.1.2 10.0.0.2 12345 8080\r\nping\n")); err != nil {
+			t.Fatal(err)
+		}
+
+		reader := bufio.NewReader(conn)
+		if line := readLine(t, reader); line != "HELLO\n" {
+			t.Errorf("Unexpected banner %q", line)
+		}
+		if line := readLine(t, reader); line != "ping\n" {
+			t.Errorf("Unexpected echo %q", line)
+		}
+	})
+
+	t.Run("when client without header wait for server", func(t *testing.T) {
+		conn, err := net.Dial("tcp", rawList.Addr().String())
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer conn.Close()
+
+		reader := bufio.NewReader(conn)
+		if line := readLine(t, reader); line != "HELLO\n" {
+			t.Errorf("Unexpected banner %q", line)
+		}
+
+		if _, err := conn.Write([]byte("ping\n")); err != nil {
+			t.Fatal(err)
+		}
+		if line := readLine(t, reader); line != "ping\n" {
+			t.Errorf("Unexpected echo %q", line)
+		}
+	})
+}
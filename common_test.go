@@ -3,7 +3,9 @@ package proxyprotocol_test
 import (
 	"bufio"
 	"bytes"
+	"encoding/binary"
 	"io"
+	"net"
 	"reflect"
 	"testing"
 
@@ -18,8 +20,12 @@ type testParserArgs struct {
 	readAll      bool
 }
 
+func newTestReader(data []byte) *bufio.Reader {
+	return bufio.NewReader(bytes.NewBuffer(data))
+}
+
 func testParser(t *testing.T, args testParserArgs) {
-	buf := bufio.NewReader(bytes.NewBuffer(args.data))
+	buf := newTestReader(args.data)
 	header, err := args.headerParser.Parse(buf)
 
 	if !reflect.DeepEqual(args.header, header) {
@@ -32,3 +38,32 @@ func testParser(t *testing.T, args testParserArgs) {
 		t.Errorf("Buffer not readed")
 	}
 }
+
+var (
+	testIPv4AddressData = []byte{
+		192, 168, 1, 2,
+		10, 0, 0, 2,
+		0x30, 0x39,
+		0x1F, 0x90,
+	}
+	testIPv4SrcAddr = &net.TCPAddr{IP: net.IP{192, 168, 1, 2}, Port: 12345}
+	testIPv4DstAddr = &net.TCPAddr{IP: net.IP{10, 0, 0, 2}, Port: 8080}
+)
+
+func buildBinaryHeader(commandVersion, protocol byte, addressData, tlvData []byte) []byte {
+	addressLen := make([]byte, 2)
+	binary.BigEndian.PutUint16(addressLen, uint16(len(addressData)+len(tlvData)))
+
+	data := append(proxyprotocol.BinarySignature, commandVersion, protocol)
+	data = append(data, addressLen...)
+	data = append(data, addressData...)
+	return append(data, tlvData...)
+}
+
+func buildTLV(tlvType byte, value []byte) []byte {
+	tlvLen := make([]byte, 2)
+	binary.BigEndian.PutUint16(tlvLen, uint16(len(value)))
+
+	data := append([]byte{tlvType}, tlvLen...)
+	return append(data, value...)
+}
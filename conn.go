@@ -2,6 +2,7 @@ package proxyprotocol
 
 import (
 	"bufio"
+	"io"
 	"net"
 	"sync"
 )
@@ -38,6 +39,7 @@ func (conn *Conn) parseHeader() {
 	conn.header, conn.headerErr = conn.headerParser.Parse(conn.readBuf)
 	if conn.headerErr != nil {
 		conn.logger.Printf("Header parse error: %s", conn.headerErr)
+		conn.header = nil
 		return
 	}
 	conn.logger.Printf("Header parsed %v", conn.header)
@@ -64,7 +66,7 @@ func (conn *Conn) Read(buf []byte) (int, error) {
 func (conn *Conn) LocalAddr() net.Addr {
 	conn.once.Do(conn.parseHeader)
 
-	if conn.trustedAddr && conn.header != nil {
+	if conn.useHeaderAddr() && conn.header.DstAddr != nil {
 		return conn.header.DstAddr
 	}
 
@@ -73,14 +75,136 @@ func (conn *Conn) LocalAddr() net.Addr {
 
 // RemoteAddr on first call parse proxyprotocol header.
 //
-// If header parser return header, then return source address from header.
+// If header parser return header with PROXY command, then return source address
+// from header.
 // Otherwise return original source address.
 func (conn *Conn) RemoteAddr() net.Addr {
 	conn.once.Do(conn.parseHeader)
 
-	if conn.trustedAddr && conn.header != nil {
+	if conn.useHeaderAddr() && conn.header.SrcAddr != nil {
 		return conn.header.SrcAddr
 	}
 
 	return conn.Conn.RemoteAddr()
 }
+
+// useHeaderAddr return true when addresses from trusted header with PROXY
+// command should be used
+func (conn *Conn) useHeaderAddr() bool {
+	return conn.trustedAddr && conn.header != nil && conn.header.Command == CommandProxy
+}
+
+// RawHeader return raw bytes of received header as they was read from
+// connection. Nil returned when header not received.
+func (conn *Conn) RawHeader() ([]byte, error) {
+	conn.once.Do(conn.parseHeader)
+
+	if conn.headerErr != nil || conn.header == nil {
+		return nil, conn.headerErr
+	}
+
+	return conn.header.Raw, nil
+}
+
+// ForwardHeader write received header verbatim into upstream connection,
+// including unknown TLVs.
+//
+// If header not received or not trusted, then ErrNoHeader returned.
+func (conn *Conn) ForwardHeader(upstream io.Writer) error {
+	header, err := conn.forwardedHeader()
+	if err != nil {
+		return err
+	}
+
+	return header.Write(upstream)
+}
+
+// ForwardHeaderWithAddrs write received header with rewritten addresses into
+// upstream connection. Header encoded in received version, TLVs kept.
+//
+// If header not received or not trusted, then ErrNoHeader returned.
+func (conn *Conn) ForwardHeaderWithAddrs(upstream io.Writer, srcAddr, dstAddr net.Addr) error {
+	header, err := conn.forwardedHeader()
+	if err != nil {
+		return err
+	}
+
+	return header.WithAddrs(srcAddr, dstAddr).Write(upstream)
+}
+
+func (conn *Conn) forwardedHeader() (*Header, error) {
+	conn.once.Do(conn.parseHeader)
+
+	if conn.headerErr != nil {
+		return nil, conn.headerErr
+	}
+
+	if !conn.trustedAddr || conn.header == nil {
+		return nil, ErrNoHeader
+	}
+
+	return conn.header, nil
+}
+
+// ConnHeader is proxyprotocol state of Conn
+type ConnHeader struct {
+	// Header is parsed header. Nil when header not received or parse failed.
+	Header *Header
+	// Err is header parse error
+	Err error
+	// Trusted is true when connection source trusted by SourceChecker
+	Trusted bool
+	// PeerAddr is address of immediate peer (e.g. load balancer)
+	PeerAddr net.Addr
+}
+
+// netConner is implemented by *tls.Conn since Go 1.18
+type netConner interface {
+	NetConn() net.Conn
+}
+
+// connUnwrapper is implemented by wrappers over net.Conn
+type connUnwrapper interface {
+	Unwrap() net.Conn
+}
+
+// HeaderFromConn find Conn under conn wrappers (*tls.Conn and any wrapper
+// with NetConn() or Unwrap() method) and return its header state. If header
+// not parsed yet, then it parsed (read from connection).
+//
+// False returned when conn not wrap Conn.
+func HeaderFromConn(conn net.Conn) (ConnHeader, bool) {
+	ppConn, found := unwrapConn(conn)
+	if !found {
+		return ConnHeader{}, false
+	}
+
+	return ppConn.connHeader(), true
+}
+
+// unwrapConn find Conn under conn wrappers without header parsing
+func unwrapConn(conn net.Conn) (*Conn, bool) {
+	for {
+		switch wrapper := conn.(type) {
+		case *Conn:
+			return wrapper, true
+		case netConner:
+			conn = wrapper.NetConn()
+		case connUnwrapper:
+			conn = wrapper.Unwrap()
+		default:
+			return nil, false
+		}
+	}
+}
+
+func (conn *Conn) connHeader() ConnHeader {
+	conn.once.Do(conn.parseHeader)
+
+	return ConnHeader{
+		Header:   conn.header,
+		Err:      conn.headerErr,
+		Trusted:  conn.trustedAddr,
+		PeerAddr: conn.Conn.RemoteAddr(),
+	}
+}
@@ -2,6 +2,7 @@ package proxyprotocol_test
 
 import (
 	"bufio"
+	"bytes"
 	"errors"
 	"net"
 	"reflect"
@@ -189,6 +190,27 @@ func TestConn_RemoteAddr(t *testing.T) {
 			}
 		})
 	})
+
+	t.Run("when header parser return header with LOCAL command", func(t *testing.T) {
+		headerParser := NewMockHeaderParser(mockCtrl)
+		readBuf := bufio.NewReaderSize(rawConn, 1400)
+		header := &proxyprotocol.Header{
+			SrcAddr: &net.TCPAddr{
+				IP: net.IPv4(1, 2, 3, 4),
+			},
+			Command: proxyprotocol.CommandLocal,
+		}
+		headerParser.EXPECT().Parse(readBuf).Return(header, nil)
+
+		trustedAddr := true
+		conn := proxyprotocol.NewConn(rawConn, logger, headerParser, trustedAddr)
+
+		remoteAddr := conn.RemoteAddr()
+
+		if !reflect.DeepEqual(remoteAddr, rawAddr) {
+			t.Errorf("Unexpected remote adder %s", remoteAddr)
+		}
+	})
 }
 
 func TestConn_LocalAddr(t *testing.T) {
@@ -262,3 +284,181 @@ func TestConn_LocalAddr(t *testing.T) {
 		})
 	})
 }
+
+func TestConn_ForwardHeader(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	rawConn := NewMockConn(mockCtrl)
+	logger := NewMockLogger(mockCtrl)
+	logger.EXPECT().Printf(gomock.Any(), gomock.Any()).AnyTimes()
+
+	rawHeader := buildBinaryHeader(
+		proxyprotocol.BinaryVersion2|proxyprotocol.BinaryCommandProxy,
+		proxyprotocol.BinaryProtocolTCPoverIPv4,
+		testIPv4AddressData,
+		buildTLV(0xE1, []byte("unknown")),
+	)
+	header := &proxyprotocol.Header{
+		SrcAddr:       testIPv4SrcAddr,
+		DstAddr:       testIPv4DstAddr,
+		Version:       proxyprotocol.Version2,
+		AddressFamily: proxyprotocol.BinaryAFInet,
+		Transport:     proxyprotocol.BinaryTPStream,
+		TLVs:          []proxyprotocol.TLV{{Type: 0xE1, Value: []byte("unknown")}},
+		Raw:           rawHeader,
+	}
+
+	newConn := func(header *proxyprotocol.Header, err error, trustedAddr bool) *proxyprotocol.Conn {
+		headerParser := NewMockHeaderParser(mockCtrl)
+		headerParser.EXPECT().Parse(gomock.Any()).Return(header, err)
+		return proxyprotocol.NewConn(rawConn, logger, headerParser, trustedAddr).(*proxyprotocol.Conn)
+	}
+
+	t.Run("when header received", func(t *testing.T) {
+		conn := newConn(header, nil, true)
+
+		if raw, err := conn.RawHeader(); err != nil || !bytes.Equal(raw, rawHeader) {
+			t.Errorf("Unexpected raw header %v, error %v", raw, err)
+		}
+
+		upstream := bytes.Buffer{}
+		if err := conn.ForwardHeader(&upstream); err != nil {
+			t.Fatalf("Unexpected error %s", err)
+		}
+		if !bytes.Equal(upstream.Bytes(), rawHeader) {
+			t.Errorf("Unexpected forwarded header %v", upstream.Bytes())
+		}
+	})
+
+	t.Run("when header forwarded with new addresses", func(t *testing.T) {
+		conn := newConn(header, nil, true)
+
+		srcAddr := &net.TCPAddr{IP: net.IPv4(10, 1, 1, 1), Port: 1000}
+		dstAddr := &net.TCPAddr{IP: net.IPv4(10, 1, 1, 2), Port: 2000}
+
+		upstream := bytes.Buffer{}
+		if err := conn.ForwardHeaderWithAddrs(&upstream, srcAddr, dstAddr); err != nil {
+			t.Fatalf("Unexpected error %s", err)
+		}
+
+		parser := proxyprotocol.NewBinaryHeaderParser(proxyprotocol.LoggerFunc(t.Logf))
+		forwardedHeader, err := parser.Parse(bufio.NewReader(&upstream))
+		if err != nil {
+			t.Fatalf("Unexpected error %s", err)
+		}
+
+		if !forwardedHeader.SrcAddr.(*net.TCPAddr).IP.Equal(srcAddr.IP) ||
+			!forwardedHeader.DstAddr.(*net.TCPAddr).IP.Equal(dstAddr.IP) ||
+			!reflect.DeepEqual(forwardedHeader.TLVs, header.TLVs) {
+			t.Errorf("Unexpected forwarded header %+v", forwardedHeader)
+		}
+	})
+
+	t.Run("when header not trusted", func(t *testing.T) {
+		conn := newConn(header, nil, false)
+
+		if err := conn.ForwardHeader(&bytes.Buffer{}); err != proxyprotocol.ErrNoHeader {
+			t.Errorf("Unexpected error %v", err)
+		}
+	})
+
+	t.Run("when header not received", func(t *testing.T) {
+		conn := newConn(nil, nil, true)
+
+		if raw, err := conn.RawHeader(); raw != nil || err != nil {
+			t.Errorf("Unexpected raw header %v, error %v", raw, err)
+		}
+
+		if err := conn.ForwardHeader(&bytes.Buffer{}); err != proxyprotocol.ErrNoHeader {
+			t.Errorf("Unexpected error %v", err)
+		}
+	})
+
+	t.Run("when header parser return error", func(t *testing.T) {
+		parseErr := errors.New("parse error")
+		conn := newConn(nil, parseErr, true)
+
+		if err := conn.ForwardHeader(&bytes.Buffer{}); err != parseErr {
+			t.Errorf("Unexpected error %v", err)
+		}
+	})
+}
+
+// netConnWrapper mimic *tls.Conn
+type netConnWrapper struct {
+	net.Conn
+}
+
+func (wrapper netConnWrapper) NetConn() net.Conn {
+	return wrapper.Conn
+}
+
+type unwrapWrapper struct {
+	net.Conn
+}
+
+func (wrapper unwrapWrapper) Unwrap() net.Conn {
+	return wrapper.Conn
+}
+
+func TestHeaderFromConn(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	peerAddr := &net.TCPAddr{IP: net.IPv4(172, 16, 0, 1), Port: 40000}
+	rawConn := NewMockConn(mockCtrl)
+	rawConn.EXPECT().RemoteAddr().Return(peerAddr).AnyTimes()
+	logger := NewMockLogger(mockCtrl)
+	logger.EXPECT().Printf(gomock.Any(), gomock.Any()).AnyTimes()
+
+	header := &proxyprotocol.Header{
+		SrcAddr: testIPv4SrcAddr,
+		DstAddr: testIPv4DstAddr,
+		Version: proxyprotocol.Version2,
+	}
+
+	newConn := func(header *proxyprotocol.Header, err error, trustedAddr bool) net.Conn {
+		headerParser := NewMockHeaderParser(mockCtrl)
+		headerParser.EXPECT().Parse(gomock.Any()).Return(header, err)
+		return proxyprotocol.NewConn(rawConn, logger, headerParser, trustedAddr)
+	}
+
+	t.Run("when conn wrapped", func(t *testing.T) {
+		conn := unwrapWrapper{netConnWrapper{newConn(header, nil, true)}}
+
+		connHeader, found := proxyprotocol.HeaderFromConn(conn)
+		expectedConnHeader := proxyprotocol.ConnHeader{
+			Header:   header,
+			Trusted:  true,
+			PeerAddr: peerAddr,
+		}
+		if !found || !reflect.DeepEqual(connHeader, expectedConnHeader) {
+			t.Errorf("Unexpected conn header %+v", connHeader)
+		}
+	})
+
+	t.Run("when header not trusted", func(t *testing.T) {
+		connHeader, found := proxyprotocol.HeaderFromConn(newConn(header, nil, false))
+		if !found || connHeader.Trusted || connHeader.Header != header {
+			t.Errorf("Unexpected conn header %+v", connHeader)
+		}
+	})
+
+	t.Run("when header parser return error", func(t *testing.T) {
+		parseErr := errors.New("parse error")
+		connHeader, found := proxyprotocol.HeaderFromConn(newConn(nil, parseErr, true))
+		if !found || connHeader.Err != parseErr || connHeader.Header != nil || connHeader.PeerAddr != peerAddr {
+			t.Errorf("Unexpected conn header %+v", connHeader)
+		}
+	})
+
+	t.Run("when conn is not proxyprotocol conn", func(t *testing.T) {
+		if _, found := proxyprotocol.HeaderFromConn(unwrapWrapper{rawConn}); found {
+			t.Error("Unexpected found")
+		}
+		if _, found := proxyprotocol.HeaderFromConn(unwrapWrapper{}); found {
+			t.Error("Unexpected found for nil conn")
+		}
+	})
+}
@@ -0,0 +1,75 @@
+package proxyprotocol
+
+import (
+	"context"
+	"net"
+	"net/http"
+)
+
+type contextKey int
+
+const (
+	headerContextKey contextKey = iota
+	connContextKey
+)
+
+// ContextWithHeader return copy of ctx with Header
+func ContextWithHeader(ctx context.Context, header *Header) context.Context {
+	return context.WithValue(ctx, headerContextKey, header)
+}
+
+// HeaderFromContext return Header stored in ctx by ContextWithHeader. Otherwise
+// return trusted header of Conn stored by ConnContext.
+func HeaderFromContext(ctx context.Context) (*Header, bool) {
+	if header, ok := ctx.Value(headerContextKey).(*Header); ok && header != nil {
+		return header, true
+	}
+
+	connHeader, found := ConnHeaderFromContext(ctx)
+	if !found || !connHeader.Trusted || connHeader.Header == nil {
+		return nil, false
+	}
+
+	return connHeader.Header, true
+}
+
+// ConnContext return copy of ctx with Conn found under conn wrappers. When conn
+// not wrap Conn, then ctx returned.
+//
+// Can be used as http.Server.ConnContext (Go 1.13+):
+//
+//	server := &http.Server{ConnContext: proxyprotocol.ConnContext}
+//
+// Header not read here, because ConnContext called from accept loop. It
+// parsed on first access from handler, when request already read.
+func ConnContext(ctx context.Context, conn net.Conn) context.Context {
+	ppConn, found := unwrapConn(conn)
+	if !found {
+		return ctx
+	}
+
+	return context.WithValue(ctx, connContextKey, ppConn)
+}
+
+// ConnHeaderFromContext return header state of Conn stored by ConnContext
+func ConnHeaderFromContext(ctx context.Context) (ConnHeader, bool) {
+	conn, ok := ctx.Value(connContextKey).(*Conn)
+	if !ok {
+		return ConnHeader{}, false
+	}
+
+	return conn.connHeader(), true
+}
+
+// HeaderFromRequest return trusted header of connection which request
+// received from. See HeaderFromContext.
+func HeaderFromRequest(req *http.Request) (*Header, bool) {
+	return HeaderFromContext(req.Context())
+}
+
+// ConnHeaderFromRequest return header state of connection which request
+// received from, including untrusted header and address of immediate peer.
+// Connection should be stored with ConnContext.
+func ConnHeaderFromRequest(req *http.Request) (ConnHeader, bool) {
+	return ConnHeaderFromContext(req.Context())
+}
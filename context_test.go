@@ -0,0 +1,118 @@
+package proxyprotocol_test
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"testing"
+
+	"github.com/c0va23/go-proxyprotocol"
+	"github.com/golang/mock/gomock"
+)
+
+func TestHeaderFromContext(t *testing.T) {
+	t.Run("when context without header", func(t *testing.T) {
+		if header, found := proxyprotocol.HeaderFromContext(context.Background()); found {
+			t.Errorf("Unexpected header %+v", header)
+		}
+	})
+
+	t.Run("when context with header", func(t *testing.T) {
+		header := &proxyprotocol.Header{SrcAddr: testIPv4SrcAddr}
+		ctx := proxyprotocol.ContextWithHeader(context.Background(), header)
+
+		contextHeader, found := proxyprotocol.HeaderFromContext(ctx)
+		if !found || contextHeader != header {
+			t.Errorf("Unexpected header %+v", contextHeader)
+		}
+	})
+
+	t.Run("when context with nil header", func(t *testing.T) {
+		ctx := proxyprotocol.ContextWithHeader(context.Background(), nil)
+
+		if header, found := proxyprotocol.HeaderFromContext(ctx); found {
+			t.Errorf("Unexpected header %+v", header)
+		}
+	})
+}
+
+func TestConnContext(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	peerAddr := &net.TCPAddr{IP: net.IPv4(172, 16, 0, 1), Port: 40000}
+	rawConn := NewMockConn(mockCtrl)
+	rawConn.EXPECT().RemoteAddr().Return(peerAddr).AnyTimes()
+	logger := NewMockLogger(mockCtrl)
+	logger.EXPECT().Printf(gomock.Any(), gomock.Any()).AnyTimes()
+
+	header := &proxyprotocol.Header{
+		SrcAddr: testIPv4SrcAddr,
+		DstAddr: testIPv4DstAddr,
+	}
+
+	// newRequest mimic http.Server, which call ConnContext before request read
+	newRequest := func(trustedAddr bool) *http.Request {
+		headerParser := NewMockHeaderParser(mockCtrl)
+		conn := proxyprotocol.NewConn(rawConn, logger, headerParser, trustedAddr)
+		ctx := proxyprotocol.ConnContext(context.Background(), unwrapWrapper{conn})
+
+		headerParser.EXPECT().Parse(gomock.Any()).Return(header, nil).MaxTimes(1)
+		req, err := http.NewRequest(http.MethodGet, "http://example.com/", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return req.WithContext(ctx)
+	}
+
+	t.Run("when header trusted", func(t *testing.T) {
+		req := newRequest(true)
+
+		if requestHeader, found := proxyprotocol.HeaderFromRequest(req); !found || requestHeader != header {
+			t.Errorf("Unexpected header %+v", requestHeader)
+		}
+
+		connHeader, found := proxyprotocol.ConnHeaderFromRequest(req)
+		if !found || connHeader.Header != header || !connHeader.Trusted || connHeader.PeerAddr != peerAddr {
+			t.Errorf("Unexpected conn header %+v", connHeader)
+		}
+	})
+
+	t.Run("when header not trusted", func(t *testing.T) {
+		req := newRequest(false)
+
+		if requestHeader, found := proxyprotocol.HeaderFromRequest(req); found {
+			t.Errorf("Unexpected header %+v", requestHeader)
+		}
+
+		connHeader, found := proxyprotocol.ConnHeaderFromRequest(req)
+		if !found || connHeader.Header != header || connHeader.Trusted {
+			t.Errorf("Unexpected conn header %+v", connHeader)
+		}
+	})
+
+	t.Run("when context with header", func(t *testing.T) {
+		req := newRequest(true)
+		otherHeader := &proxyprotocol.Header{SrcAddr: testIPv4DstAddr}
+		req = req.WithContext(proxyprotocol.ContextWithHeader(req.Context(), otherHeader))
+
+		if requestHeader, found := proxyprotocol.HeaderFromRequest(req); !found || requestHeader != otherHeader {
+			t.Errorf("Unexpected header %+v", requestHeader)
+		}
+	})
+
+	t.Run("when conn is not proxyprotocol conn", func(t *testing.T) {
+		ctx := context.Background()
+		if connCtx := proxyprotocol.ConnContext(ctx, rawConn); connCtx != ctx {
+			t.Errorf("Unexpected context %v", connCtx)
+		}
+
+		req, err := http.NewRequest(http.MethodGet, "http://example.com/", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, found := proxyprotocol.ConnHeaderFromRequest(req); found {
+			t.Error("Unexpected conn header")
+		}
+	})
+}
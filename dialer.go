@@ -0,0 +1,111 @@
+package proxyprotocol
+
+import (
+	"context"
+	"net"
+	"time"
+)
+
+// Dialer wrap net.Dialer and write proxyprotocol header into each established
+// connection before return it.
+//
+// When Header is nil, then header with LOCAL command sent. It can be used for
+// health checks.
+type Dialer struct {
+	net.Dialer
+	// Version is Version1 or Version2
+	Version byte
+	Header  *Header
+	// BinaryFormatOptions used to encode Version2 headers
+	BinaryFormatOptions BinaryFormatOptions
+}
+
+// NewDialer construct Dialer for header version
+func NewDialer(version byte) Dialer {
+	return Dialer{
+		Version: version,
+	}
+}
+
+// WithHeader copy Dialer and set Header
+func (dialer Dialer) WithHeader(header *Header) Dialer {
+	newDialer := dialer
+	newDialer.Header = header
+	return newDialer
+}
+
+// WithConn copy Dialer and set Header with addresses of inbound connection.
+// For Conn with trusted header this is addresses from header.
+func (dialer Dialer) WithConn(conn net.Conn) Dialer {
+	return dialer.WithHeader(&Header{
+		SrcAddr: conn.RemoteAddr(),
+		DstAddr: conn.LocalAddr(),
+	})
+}
+
+// WithLocalCommand copy Dialer and set Header with LOCAL command
+func (dialer Dialer) WithLocalCommand() Dialer {
+	return dialer.WithHeader(&Header{Command: CommandLocal})
+}
+
+// Dial connect to address and write header
+func (dialer Dialer) Dial(network, address string) (net.Conn, error) {
+	return dialer.DialContext(context.Background(), network, address)
+}
+
+// DialContext connect to address and write header. Context deadline applied
+// to header writing.
+func (dialer Dialer) DialContext(ctx context.Context, network, address string) (net.Conn, error) {
+	headerBuf, err := dialer.formatHeader()
+	if err != nil {
+		return nil, err
+	}
+
+	conn, err := dialer.Dialer.DialContext(ctx, network, address)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := writeHeader(ctx, conn, headerBuf); err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	return conn, nil
+}
+
+// writeHeader write header into connection with context deadline
+func writeHeader(ctx context.Context, conn net.Conn, headerBuf []byte) error {
+	deadline, hasDeadline := ctx.Deadline()
+	if hasDeadline {
+		if err := conn.SetWriteDeadline(deadline); err != nil {
+			return err
+		}
+	}
+
+	if _, err := conn.Write(headerBuf); err != nil {
+		return err
+	}
+
+	if hasDeadline {
+		return conn.SetWriteDeadline(time.Time{})
+	}
+
+	return nil
+}
+
+func (dialer Dialer) formatHeader() ([]byte, error) {
+	header := dialer.Header
+	if header == nil {
+		header = &Header{Command: CommandLocal}
+	}
+
+	switch dialer.Version {
+	case Version1:
+		return header.FormatV1()
+	case Version2:
+		return header.FormatV2WithOptions(dialer.BinaryFormatOptions)
+	default:
+		return nil, ErrUnknownVersion
+	}
+}
@@ -0,0 +1,130 @@
+package proxyprotocol_test
+
+import (
+	"bufio"
+	"context"
+	"net"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/c0va23/go-proxyprotocol"
+)
+
+func acceptHeader(t *testing.T, listener net.Listener) chan *proxyprotocol.Header {
+	headers := make(chan *proxyprotocol.Header, 1)
+	go func() {
+		defer close(headers)
+
+		conn, err := listener.Accept()
+		if err != nil {
+			t.Errorf("Accept error: %s", err)
+			return
+		}
+		defer conn.Close()
+
+		headerParser := proxyprotocol.DefaultFallbackHeaderParserBuilder.Build(proxyprotocol.LoggerFunc(t.Logf))
+		header, err := headerParser.Parse(bufio.NewReader(conn))
+		if err != nil {
+			t.Errorf("Parse error: %s", err)
+			return
+		}
+		headers <- header
+	}()
+	return headers
+}
+
+func TestDialer_DialContext(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+
+	header := &proxyprotocol.Header{
+		SrcAddr: testIPv4SrcAddr,
+		DstAddr: testIPv4DstAddr,
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	for _, version := range []byte{proxyprotocol.Version1, proxyprotocol.Version2} {
+		headers := acceptHeader(t, listener)
+
+		dialer := proxyprotocol.NewDialer(version).WithHeader(header)
+		conn, err := dialer.DialContext(ctx, "tcp", listener.Addr().String())
+		if err != nil {
+			t.Fatalf("Dial error: %s", err)
+		}
+
+		receivedHeader := <-headers
+		conn.Close()
+
+		if receivedHeader == nil || receivedHeader.Version != version {
+			t.Fatalf("Unexpected header %+v", receivedHeader)
+		}
+
+		if !reflect.DeepEqual(receivedHeader.SrcAddr.String(), header.SrcAddr.String()) ||
+			!reflect.DeepEqual(receivedHeader.DstAddr.String(), header.DstAddr.String()) {
+			t.Errorf("Unexpected addresses %s, %s", receivedHeader.SrcAddr, receivedHeader.DstAddr)
+		}
+	}
+}
+
+func TestDialer_WithLocalCommand(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+
+	headers := acceptHeader(t, listener)
+
+	conn, err := proxyprotocol.NewDialer(proxyprotocol.Version2).
+		WithLocalCommand().
+		Dial("tcp", listener.Addr().String())
+	if err != nil {
+		t.Fatalf("Dial error: %s", err)
+	}
+	defer conn.Close()
+
+	receivedHeader := <-headers
+	if receivedHeader == nil || receivedHeader.Command != proxyprotocol.CommandLocal {
+		t.Errorf("Unexpected header %+v", receivedHeader)
+	}
+}
+
+func TestDialer_WithConn(t *testing.T) {
+	serverConn, clientConn := net.Pipe()
+	defer serverConn.Close()
+	defer clientConn.Close()
+
+	dialer := proxyprotocol.NewDialer(proxyprotocol.Version2).WithConn(serverConn)
+
+	expectedHeader := &proxyprotocol.Header{
+		SrcAddr: serverConn.RemoteAddr(),
+		DstAddr: serverConn.LocalAddr(),
+	}
+	if !reflect.DeepEqual(dialer.Header, expectedHeader) {
+		t.Errorf("Unexpected header %+v", dialer.Header)
+	}
+}
+
+func TestDialer_Dial_errors(t *testing.T) {
+	t.Run("unknown version", func(t *testing.T) {
+		if _, err := proxyprotocol.NewDialer(0).Dial("tcp", "127.0.0.1:1"); err != proxyprotocol.ErrUnknownVersion {
+			t.Errorf("Unexpected error %v", err)
+		}
+	})
+
+	t.Run("unsupported address", func(t *testing.T) {
+		dialer := proxyprotocol.NewDialer(proxyprotocol.Version1).WithHeader(&proxyprotocol.Header{
+			SrcAddr: &net.UnixAddr{Name: "/tmp/client.sock", Net: "unix"},
+			DstAddr: &net.UnixAddr{Name: "/tmp/server.sock", Net: "unix"},
+		})
+		if _, err := dialer.Dial("tcp", "127.0.0.1:1"); err != proxyprotocol.ErrUnsupportedAddress {
+			t.Errorf("Unexpected error %v", err)
+		}
+	})
+}
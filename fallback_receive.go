@@ -61,7 +61,8 @@ func NewFallbackHeaderParser(logger Logger, headerParsers ...HeaderParser) Fallb
 
 // Parse iterate over headerParsers call Parse().
 //
-// If any parser return not nil or not ErrInvalidSignature error, then return its error.
+// If any parser return not nil or not ErrInvalidSignature error, then return its
+// header and error.
 //
 // If any parser return nil error, then return header.
 //
@@ -77,7 +78,7 @@ func (parser FallbackHeaderParser) Parse(buf *bufio.Reader) (*Header, error) {
 			continue
 		default:
 			parser.Logger.Printf("Parse header error: %s", err)
-			return nil, err
+			return header, err
 		}
 	}
 	return nil, ErrInvalidHeader
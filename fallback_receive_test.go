@@ -137,6 +137,13 @@ func TestFallbackHeaderParser_Parse(t *testing.T) {
 		testHeaderParseResult(t, header, nil, err, parseErr)
 	})
 
+	t.Run("when first header parser return header with error", func(t *testing.T) {
+		firstHeaderParser.EXPECT().Parse(readBuf).Return(expectedHeader, proxyprotocol.ErrChecksumMismatch)
+
+		header, err := fallbackHeaderParser.Parse(readBuf)
+		testHeaderParseResult(t, header, expectedHeader, err, proxyprotocol.ErrChecksumMismatch)
+	})
+
 	t.Run("when first header parser return header", func(t *testing.T) {
 		firstHeaderParser.EXPECT().Parse(readBuf).Return(expectedHeader, nil)
 
@@ -0,0 +1,50 @@
+package proxyprotocol
+
+import (
+	"errors"
+	"io"
+	"net"
+)
+
+// ErrNoHeader returned when connection not have trusted header for forwarding
+var ErrNoHeader = errors.New("no trusted header")
+
+// WithAddrs copy header and set addresses. Raw bytes and address family of
+// copy reset, so it can be encoded with new addresses. TLVs kept.
+func (header *Header) WithAddrs(srcAddr, dstAddr net.Addr) *Header {
+	newHeader := *header
+	newHeader.SrcAddr = srcAddr
+	newHeader.DstAddr = dstAddr
+	newHeader.AddressFamily = BinaryAFUnspec
+	newHeader.Transport = BinaryTPUnspec
+	newHeader.Raw = nil
+	return &newHeader
+}
+
+// Format return raw header bytes, when header contain them. Otherwise header
+// encoded into format of header version.
+func (header *Header) Format() ([]byte, error) {
+	if header.Raw != nil {
+		return header.Raw, nil
+	}
+
+	switch header.Version {
+	case Version1:
+		return header.FormatV1()
+	case Version2:
+		return header.FormatV2()
+	default:
+		return nil, ErrUnknownVersion
+	}
+}
+
+// Write write header returned by Format into writer
+func (header *Header) Write(writer io.Writer) error {
+	headerBuf, err := header.Format()
+	if err != nil {
+		return err
+	}
+
+	_, err = writer.Write(headerBuf)
+	return err
+}
@@ -0,0 +1,94 @@
+package proxyprotocol_test
+
+import (
+	"bufio"
+	"bytes"
+	"net"
+	"reflect"
+	"testing"
+
+	"github.com/c0va23/go-proxyprotocol"
+)
+
+func TestHeader_WithAddrs(t *testing.T) {
+	header := &proxyprotocol.Header{
+		SrcAddr:       testIPv4SrcAddr,
+		DstAddr:       testIPv4DstAddr,
+		Version:       proxyprotocol.Version2,
+		AddressFamily: proxyprotocol.BinaryAFInet,
+		Transport:     proxyprotocol.BinaryTPStream,
+		TLVs:          []proxyprotocol.TLV{{Type: 0xE1, Value: []byte("value")}},
+		Raw:           []byte("raw"),
+	}
+
+	srcAddr := &net.TCPAddr{IP: net.ParseIP("::1"), Port: 1234}
+	dstAddr := &net.TCPAddr{IP: net.ParseIP("::2"), Port: 80}
+	newHeader := header.WithAddrs(srcAddr, dstAddr)
+
+	expectedHeader := &proxyprotocol.Header{
+		SrcAddr: srcAddr,
+		DstAddr: dstAddr,
+		Version: proxyprotocol.Version2,
+		TLVs:    header.TLVs,
+	}
+	if !reflect.DeepEqual(newHeader, expectedHeader) {
+		t.Errorf("Unexpected header %+v", newHeader)
+	}
+
+	if header.SrcAddr != testIPv4SrcAddr || header.Raw == nil {
+		t.Errorf("Source header changed %+v", header)
+	}
+}
+
+func TestHeader_Format(t *testing.T) {
+	t.Run("when header with raw bytes", func(t *testing.T) {
+		header := &proxyprotocol.Header{
+			Version: proxyprotocol.Version1,
+			Raw:     []byte("raw"),
+		}
+
+		headerBuf, err := header.Format()
+		if err != nil {
+			t.Fatalf("Unexpected error %s", err)
+		}
+		if !bytes.Equal(headerBuf, header.Raw) {
+			t.Errorf("Unexpected header %q", headerBuf)
+		}
+	})
+
+	t.Run("when unknown version", func(t *testing.T) {
+		header := &proxyprotocol.Header{}
+
+		if _, err := header.Format(); err != proxyprotocol.ErrUnknownVersion {
+			t.Errorf("Unexpected error %s", err)
+		}
+	})
+
+	for _, version := range []byte{proxyprotocol.Version1, proxyprotocol.Version2} {
+		header := &proxyprotocol.Header{
+			SrcAddr: testIPv4SrcAddr,
+			DstAddr: testIPv4DstAddr,
+			Version: version,
+		}
+
+		headerBuf := bytes.Buffer{}
+		if err := header.Write(&headerBuf); err != nil {
+			t.Fatalf("Unexpected error %s", err)
+		}
+
+		parser := proxyprotocol.NewFallbackHeaderParser(
+			proxyprotocol.LoggerFunc(t.Logf),
+			proxyprotocol.NewTextHeaderParser(proxyprotocol.LoggerFunc(t.Logf)),
+			proxyprotocol.NewBinaryHeaderParser(proxyprotocol.LoggerFunc(t.Logf)),
+		)
+		parsedHeader, err := parser.Parse(bufio.NewReader(&headerBuf))
+		if err != nil {
+			t.Fatalf("Unexpected error %s", err)
+		}
+
+		if parsedHeader.Version != version ||
+			parsedHeader.SrcAddr.String() != testIPv4SrcAddr.String() {
+			t.Errorf("Unexpected header %+v", parsedHeader)
+		}
+	}
+}
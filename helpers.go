@@ -20,6 +20,13 @@ var BinaryHeaderParserBuilder = HeaderParserBuilderFunc(func(logger Logger) Head
 	return NewBinaryHeaderParser(logger)
 })
 
+// NewBinaryHeaderParserBuilder build BinaryHeaderParser with TLVDecoderRegistry
+func NewBinaryHeaderParserBuilder(tlvDecoders *TLVDecoderRegistry) HeaderParserBuilder {
+	return HeaderParserBuilderFunc(func(logger Logger) HeaderParser {
+		return NewBinaryHeaderParser(logger).WithTLVDecoderRegistry(tlvDecoders)
+	})
+}
+
 // StubHeaderParserBuilder build StubHeaderParser
 var StubHeaderParserBuilder = HeaderParserBuilderFunc(func(logger Logger) HeaderParser {
 	return NewStubHeaderParser()
@@ -38,6 +38,22 @@ func TestBinaryHeaderParserBuilder_Build(t *testing.T) {
 	}
 }
 
+func TestNewBinaryHeaderParserBuilder(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	logger := NewMockLogger(mockCtrl)
+	registry := proxyprotocol.NewTLVDecoderRegistry()
+
+	headerParser := proxyprotocol.NewBinaryHeaderParserBuilder(registry).Build(logger)
+
+	expectedHeaderParser := proxyprotocol.NewBinaryHeaderParser(logger).WithTLVDecoderRegistry(registry)
+
+	if headerParser != expectedHeaderParser {
+		t.Errorf("Unexpected header parser %v", headerParser)
+	}
+}
+
 func TestStubHeaderParserBuilder_Build(t *testing.T) {
 	mockCtrl := gomock.NewController(t)
 	defer mockCtrl.Finish()
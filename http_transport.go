@@ -0,0 +1,242 @@
+package proxyprotocol
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// DefaultHTTPTransportIdleTimeout used by HTTPTransport when IdleTimeout not set
+var DefaultHTTPTransportIdleTimeout = 90 * time.Second
+
+// HTTPTransport is http.RoundTripper which send proxyprotocol header on each
+// upstream connection. It can be used as httputil.ReverseProxy.Transport.
+//
+// Header source address taken from Header stored in request context (see
+// ContextWithHeader) or from Request.RemoteAddr. Destination address taken from
+// the same Header or from http.LocalAddrContextKey.
+//
+// Header is bound to single TCP connection, so connections pooled per client
+// address: each client use own http.Transport. Transports without in-flight
+// requests and not used longer than IdleTimeout dropped.
+type HTTPTransport struct {
+	Dialer Dialer
+	// NewTransport construct http.Transport for each client. DialContext of
+	// returned transport replaced. When nil, then transport with default
+	// timeouts used.
+	NewTransport func() *http.Transport
+	IdleTimeout  time.Duration
+
+	mutex      sync.Mutex
+	transports map[string]*clientTransport
+	lastSweep  time.Time
+}
+
+type clientTransport struct {
+	*http.Transport
+	lastUsed time.Time
+	// inFlight count requests which response body not closed yet
+	inFlight int
+}
+
+// NewHTTPTransport construct HTTPTransport with Dialer
+func NewHTTPTransport(dialer Dialer) *HTTPTransport {
+	return &HTTPTransport{
+		Dialer: dialer,
+	}
+}
+
+// RoundTrip send request over connection of request client
+func (transport *HTTPTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	header := requestHeader(req)
+	client := transport.acquire(header)
+
+	res, err := client.RoundTrip(req)
+	if err != nil {
+		transport.release(client)
+		return nil, err
+	}
+
+	release := func() { transport.release(client) }
+	if body, ok := res.Body.(io.ReadWriteCloser); ok {
+		// Keep body writable for protocol upgrade (101 Switching Protocols)
+		res.Body = &releaseReadWriteCloser{ReadWriteCloser: body, release: release}
+	} else {
+		res.Body = &releaseReadCloser{ReadCloser: res.Body, release: release}
+	}
+
+	return res, nil
+}
+
+// CloseIdleConnections close idle connections of all clients and drop their
+// transports
+func (transport *HTTPTransport) CloseIdleConnections() {
+	transport.mutex.Lock()
+	defer transport.mutex.Unlock()
+
+	for key, client := range transport.transports {
+		client.CloseIdleConnections()
+		delete(transport.transports, key)
+	}
+}
+
+// acquire return transport of header client and count request as in-flight
+func (transport *HTTPTransport) acquire(header *Header) *clientTransport {
+	key := headerKey(header)
+	now := time.Now()
+
+	transport.mutex.Lock()
+	defer transport.mutex.Unlock()
+
+	transport.sweep(now)
+
+	client, found := transport.transports[key]
+	if !found {
+		client = &clientTransport{Transport: transport.newTransport(header)}
+		if transport.transports == nil {
+			transport.transports = make(map[string]*clientTransport)
+		}
+		transport.transports[key] = client
+	}
+	client.lastUsed = now
+	client.inFlight++
+
+	return client
+}
+
+// release finish in-flight request of client
+func (transport *HTTPTransport) release(client *clientTransport) {
+	transport.mutex.Lock()
+	defer transport.mutex.Unlock()
+
+	client.inFlight--
+	client.lastUsed = time.Now()
+}
+
+// sweep drop transports without in-flight requests not used longer than idle
+// timeout
+func (transport *HTTPTransport) sweep(now time.Time) {
+	idleTimeout := transport.IdleTimeout
+	if idleTimeout <= 0 {
+		idleTimeout = DefaultHTTPTransportIdleTimeout
+	}
+
+	if now.Sub(transport.lastSweep) < idleTimeout {
+		return
+	}
+	transport.lastSweep = now
+
+	for key, client := range transport.transports {
+		if client.inFlight == 0 && now.Sub(client.lastUsed) >= idleTimeout {
+			client.CloseIdleConnections()
+			delete(transport.transports, key)
+		}
+	}
+}
+
+func (transport *HTTPTransport) newTransport(header *Header) *http.Transport {
+	var httpTransport *http.Transport
+	if transport.NewTransport != nil {
+		httpTransport = transport.NewTransport()
+	} else {
+		httpTransport = &http.Transport{
+			MaxIdleConns:          100,
+			IdleConnTimeout:       DefaultHTTPTransportIdleTimeout,
+			TLSHandshakeTimeout:   10 * time.Second,
+			ExpectContinueTimeout: 1 * time.Second,
+		}
+	}
+
+	dialer := transport.Dialer.WithHeader(header)
+	httpTransport.DialContext = func(ctx context.Context, network, address string) (net.Conn, error) {
+		return dialer.DialContext(ctx, network, address)
+	}
+
+	return httpTransport
+}
+
+// releaseReadCloser call release once on Close
+type releaseReadCloser struct {
+	io.ReadCloser
+	once    sync.Once
+	release func()
+}
+
+func (body *releaseReadCloser) Close() error {
+	err := body.ReadCloser.Close()
+	body.once.Do(body.release)
+	return err
+}
+
+// releaseReadWriteCloser call release once on Close
+type releaseReadWriteCloser struct {
+	io.ReadWriteCloser
+	once    sync.Once
+	release func()
+}
+
+func (body *releaseReadWriteCloser) Close() error {
+	err := body.ReadWriteCloser.Close()
+	body.once.Do(body.release)
+	return err
+}
+
+// requestHeader build header for upstream connection of request. When client
+// address unknown, then header with LOCAL command returned.
+func requestHeader(req *http.Request) *Header {
+	ctx := req.Context()
+
+	if header, found := HeaderFromContext(ctx); found &&
+		header.Command == CommandProxy && header.SrcAddr != nil && header.DstAddr != nil {
+		return &Header{
+			SrcAddr: header.SrcAddr,
+			DstAddr: header.DstAddr,
+		}
+	}
+
+	srcAddr := parseTCPAddr(req.RemoteAddr)
+	if srcAddr == nil {
+		return &Header{Command: CommandLocal}
+	}
+
+	srcIPv4 := srcAddr.IP.To4() != nil
+	dstAddr, _ := ctx.Value(http.LocalAddrContextKey).(*net.TCPAddr)
+	if dstAddr == nil || (dstAddr.IP.To4() != nil) != srcIPv4 {
+		dstAddr = &net.TCPAddr{IP: net.IPv6unspecified}
+		if srcIPv4 {
+			dstAddr.IP = net.IPv4zero
+		}
+	}
+
+	return &Header{
+		SrcAddr: srcAddr,
+		DstAddr: dstAddr,
+	}
+}
+
+// parseTCPAddr parse "ip:port" address without name resolving
+func parseTCPAddr(address string) *net.TCPAddr {
+	host, portStr, err := net.SplitHostPort(address)
+	if err != nil {
+		return nil
+	}
+
+	ip := net.ParseIP(host)
+	port, err := strconv.ParseUint(portStr, 10, textPortBitSize)
+	if ip == nil || err != nil {
+		return nil
+	}
+
+	return &net.TCPAddr{IP: ip, Port: int(port)}
+}
+
+func headerKey(header *Header) string {
+	if header.Command == CommandLocal {
+		return header.Command.String()
+	}
+	return header.SrcAddr.String() + " " + header.DstAddr.String()
+}
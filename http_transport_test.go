@@ -0,0 +1,152 @@
+package proxyprotocol_test
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/c0va23/go-proxyprotocol"
+)
+
+func startProxyProtocolHTTPServer(t *testing.T) (string, func()) {
+	rawListener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	listener := proxyprotocol.NewDefaultListener(rawListener)
+	server := &http.Server{
+		Handler: http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+			fmt.Fprint(res, req.RemoteAddr)
+		}),
+	}
+	go server.Serve(listener) // nolint: errcheck
+
+	return "http://" + rawListener.Addr().String(), func() {
+		server.Close()
+	}
+}
+
+func TestHTTPTransport_RoundTrip(t *testing.T) {
+	serverURL, stopServer := startProxyProtocolHTTPServer(t)
+	defer stopServer()
+
+	transport := proxyprotocol.NewHTTPTransport(proxyprotocol.NewDialer(proxyprotocol.Version2))
+	defer transport.CloseIdleConnections()
+
+	roundTrip := func(t *testing.T, req *http.Request) string {
+		res, err := transport.RoundTrip(req)
+		if err != nil {
+			t.Fatalf("Round trip error: %s", err)
+		}
+		defer res.Body.Close()
+
+		body, err := ioutil.ReadAll(res.Body)
+		if err != nil {
+			t.Fatalf("Read body error: %s", err)
+		}
+		return string(body)
+	}
+
+	t.Run("client address from RemoteAddr", func(t *testing.T) {
+		for _, remoteAddr := range []string{"192.168.1.2:12345", "192.168.1.3:12345", "192.168.1.2:12345"} {
+			req, err := http.NewRequest(http.MethodGet, serverURL, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			req.RemoteAddr = remoteAddr
+
+			if body := roundTrip(t, req); body != remoteAddr {
+				t.Errorf("Unexpected remote addr %s, expected %s", body, remoteAddr)
+			}
+		}
+	})
+
+	t.Run("client address from context header", func(t *testing.T) {
+		req, err := http.NewRequest(http.MethodGet, serverURL, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.RemoteAddr = "10.0.0.1:1234"
+
+		ctx := proxyprotocol.ContextWithHeader(context.Background(), &proxyprotocol.Header{
+			SrcAddr: testIPv4SrcAddr,
+			DstAddr: testIPv4DstAddr,
+		})
+
+		if body := roundTrip(t, req.WithContext(ctx)); body != testIPv4SrcAddr.String() {
+			t.Errorf("Unexpected remote addr %s", body)
+		}
+	})
+
+	t.Run("unknown client address", func(t *testing.T) {
+		req, err := http.NewRequest(http.MethodGet, serverURL, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		body := roundTrip(t, req)
+		if host, _, _ := net.SplitHostPort(body); host != "127.0.0.1" {
+			t.Errorf("Unexpected remote addr %s", body)
+		}
+	})
+}
+
+func TestHTTPTransport_IdleTimeout(t *testing.T) {
+	serverURL, stopServer := startProxyProtocolHTTPServer(t)
+	defer stopServer()
+
+	newTransportCount := 0
+	transport := proxyprotocol.NewHTTPTransport(proxyprotocol.NewDialer(proxyprotocol.Version2))
+	transport.IdleTimeout = 50 * time.Millisecond
+	transport.NewTransport = func() *http.Transport {
+		newTransportCount++
+		return &http.Transport{IdleConnTimeout: time.Second}
+	}
+	defer transport.CloseIdleConnections()
+
+	roundTrip := func(t *testing.T, remoteAddr string) *http.Response {
+		req, err := http.NewRequest(http.MethodGet, serverURL, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.RemoteAddr = remoteAddr
+
+		res, err := transport.RoundTrip(req)
+		if err != nil {
+			t.Fatalf("Round trip error: %s", err)
+		}
+		return res
+	}
+
+	t.Run("transport with in-flight request kept", func(t *testing.T) {
+		newTransportCount = 0
+
+		res := roundTrip(t, "192.168.1.2:12345")
+		time.Sleep(2 * transport.IdleTimeout)
+		roundTrip(t, "192.168.1.3:12345").Body.Close()
+		res.Body.Close()
+		roundTrip(t, "192.168.1.2:12345").Body.Close()
+
+		if newTransportCount != 2 {
+			t.Errorf("Unexpected transports count %d", newTransportCount)
+		}
+	})
+
+	t.Run("idle transport dropped", func(t *testing.T) {
+		newTransportCount = 0
+
+		roundTrip(t, "192.168.1.4:12345").Body.Close()
+		time.Sleep(2 * transport.IdleTimeout)
+		roundTrip(t, "192.168.1.5:12345").Body.Close()
+		roundTrip(t, "192.168.1.4:12345").Body.Close()
+
+		if newTransportCount != 3 {
+			t.Errorf("Unexpected transports count %d", newTransportCount)
+		}
+	})
+}
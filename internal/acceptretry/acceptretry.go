@@ -0,0 +1,50 @@
+// Package acceptretry accept connections with retry of temporary errors.
+package acceptretry
+
+import (
+	"net"
+	"time"
+)
+
+// Retry delays
+const (
+	MinDelay = 5 * time.Millisecond
+	MaxDelay = time.Second
+)
+
+// Logger log retries
+type Logger interface {
+	Printf(format string, v ...interface{})
+}
+
+// Accept call listener.Accept. Temporary errors (like EMFILE) logged and
+// Accept retried with delay doubled from MinDelay up to MaxDelay, like
+// http.Server.Serve do. Permanent error returned.
+func Accept(listener net.Listener, logger Logger) (net.Conn, error) {
+	var delay time.Duration
+	for {
+		conn, err := listener.Accept()
+		if err == nil {
+			return conn, nil
+		}
+
+		netErr, ok := err.(net.Error)
+		if !ok || !netErr.Temporary() {
+			return nil, err
+		}
+
+		delay = nextDelay(delay)
+		logger.Printf("Accept error: %s; retrying in %s", err, delay)
+		time.Sleep(delay)
+	}
+}
+
+func nextDelay(delay time.Duration) time.Duration {
+	if delay == 0 {
+		return MinDelay
+	}
+	if delay *= 2; delay > MaxDelay {
+		return MaxDelay
+	}
+	return delay
+}
@@ -0,0 +1,64 @@
+package acceptretry_test
+
+import (
+	"errors"
+	"net"
+	"testing"
+
+	"github.com/c0va23/go-proxyprotocol/internal/acceptretry"
+)
+
+type temporaryError struct{}
+
+func (temporaryError) Error() string   { return "too many open files" }
+func (temporaryError) Timeout() bool   { return false }
+func (temporaryError) Temporary() bool { return true }
+
+// stubListener return errors from list, then conn
+type stubListener struct {
+	net.Listener
+	errs []error
+	conn net.Conn
+}
+
+func (listener *stubListener) Accept() (net.Conn, error) {
+	if len(listener.errs) == 0 {
+		return listener.conn, nil
+	}
+	err := listener.errs[0]
+	listener.errs = listener.errs[1:]
+	return nil, err
+}
+
+type loggerFunc func(format string, v ...interface{})
+
+func (f loggerFunc) Printf(format string, v ...interface{}) { f(format, v...) }
+
+func TestAccept(t *testing.T) {
+	serverConn, clientConn := net.Pipe()
+	defer serverConn.Close()
+	defer clientConn.Close()
+
+	t.Run("when temporary errors", func(t *testing.T) {
+		retries := 0
+		listener := &stubListener{errs: []error{temporaryError{}, temporaryError{}}, conn: serverConn}
+		logger := loggerFunc(func(format string, v ...interface{}) {
+			retries++
+			t.Logf(format, v...)
+		})
+
+		conn, err := acceptretry.Accept(listener, logger)
+		if err != nil || conn != serverConn || retries != 2 {
+			t.Errorf("Unexpected result %v, %v after %d retries", conn, err, retries)
+		}
+	})
+
+	t.Run("when permanent error", func(t *testing.T) {
+		permanentErr := errors.New("use of closed network connection")
+		listener := &stubListener{errs: []error{temporaryError{}, permanentErr}, conn: serverConn}
+
+		if conn, err := acceptretry.Accept(listener, loggerFunc(t.Logf)); conn != nil || err != permanentErr {
+			t.Errorf("Unexpected result %v, %v", conn, err)
+		}
+	})
+}
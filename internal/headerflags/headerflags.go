@@ -0,0 +1,205 @@
+// Package headerflags build proxyprotocol header from command line flags
+package headerflags
+
+import (
+	"encoding/hex"
+	"errors"
+	"flag"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+
+	"github.com/c0va23/go-proxyprotocol"
+)
+
+// Flag errors
+var (
+	ErrInvalidTLV     = errors.New("invalid TLV, expected TYPE=VALUE or TYPE=hex:VALUE")
+	ErrUnknownNetwork = errors.New("unknown network")
+	ErrUnknownFamily  = errors.New("unknown address family")
+)
+
+// Flags contain header fields set by flags
+type Flags struct {
+	Version   uint
+	Network   string
+	Family    string
+	SrcAddr   string
+	DstAddr   string
+	Local     bool
+	TLVs      TLVList
+	Authority string
+	ALPN      string
+	UniqueID  string
+	NetNS     string
+	Align     int
+	Checksum  bool
+	Malform   string
+}
+
+// Register define flags in flag set
+func (flags *Flags) Register(flagSet *flag.FlagSet) {
+	flagSet.UintVar(&flags.Version, "version", 2, "Header version (1 or 2)")
+	flagSet.StringVar(&flags.Network, "network", "tcp", "Address network: tcp, udp, unix or unixgram")
+	flagSet.StringVar(&flags.Family, "family", "", "Force address family: inet or inet6")
+	flagSet.StringVar(&flags.SrcAddr, "src", "", "Source address (host:port or unix path). Empty for UNKNOWN/UNSPEC")
+	flagSet.StringVar(&flags.DstAddr, "dst", "", "Destination address (host:port or unix path)")
+	flagSet.BoolVar(&flags.Local, "local", false, "Use LOCAL command")
+	flagSet.Var(&flags.TLVs, "tlv", "TLV as TYPE=VALUE or TYPE=hex:VALUE (repeatable)")
+	flagSet.StringVar(&flags.Authority, "authority", "", "AUTHORITY TLV value")
+	flagSet.StringVar(&flags.ALPN, "alpn", "", "ALPN TLV value")
+	flagSet.StringVar(&flags.UniqueID, "unique-id", "", "UNIQUE_ID TLV value")
+	flagSet.StringVar(&flags.NetNS, "netns", "", "NETNS TLV value")
+	flagSet.IntVar(&flags.Align, "align", 0, "Align v2 header with NOOP TLV")
+	flagSet.BoolVar(&flags.Checksum, "checksum", false, "Add CRC32C TLV to v2 header")
+	flagSet.StringVar(&flags.Malform, "malform", MalformNone, MalformUsage())
+}
+
+// Header build header from flags
+func (flags *Flags) Header() (*proxyprotocol.Header, error) {
+	header := &proxyprotocol.Header{
+		Version: byte(flags.Version),
+	}
+	if flags.Local {
+		header.Command = proxyprotocol.CommandLocal
+	}
+
+	switch flags.Family {
+	case "":
+	case "inet":
+		header.AddressFamily = proxyprotocol.BinaryAFInet
+	case "inet6":
+		header.AddressFamily = proxyprotocol.BinaryAFInet6
+	default:
+		return nil, ErrUnknownFamily
+	}
+
+	var err error
+	if flags.SrcAddr != "" || flags.DstAddr != "" {
+		if header.SrcAddr, err = ParseAddr(flags.Network, flags.SrcAddr); err != nil {
+			return nil, err
+		}
+		if header.DstAddr, err = ParseAddr(flags.Network, flags.DstAddr); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, tlv := range []proxyprotocol.TLV{
+		{Type: proxyprotocol.TLVTypeALPN, Value: []byte(flags.ALPN)},
+		{Type: proxyprotocol.TLVTypeAuthority, Value: []byte(flags.Authority)},
+		{Type: proxyprotocol.TLVTypeUniqueID, Value: []byte(flags.UniqueID)},
+		{Type: proxyprotocol.TLVTypeNetNS, Value: []byte(flags.NetNS)},
+	} {
+		if len(tlv.Value) > 0 {
+			header.TLVs = append(header.TLVs, tlv)
+		}
+	}
+	header.TLVs = append(header.TLVs, flags.TLVs...)
+
+	return header, nil
+}
+
+// Format build header, encode it into version from flags and malform it
+// when variant set
+func (flags *Flags) Format() ([]byte, error) {
+	header, err := flags.Header()
+	if err != nil {
+		return nil, err
+	}
+
+	var headerBuf []byte
+	switch header.Version {
+	case proxyprotocol.Version1:
+		headerBuf, err = header.FormatV1()
+	case proxyprotocol.Version2:
+		headerBuf, err = header.FormatV2WithOptions(proxyprotocol.BinaryFormatOptions{
+			Align:    flags.Align,
+			Checksum: flags.Checksum || flags.Malform == MalformChecksum,
+		})
+	default:
+		return nil, proxyprotocol.ErrUnknownVersion
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return Malform(headerBuf, flags.Malform)
+}
+
+// ParseAddr parse address of network without name resolving
+func ParseAddr(network, address string) (net.Addr, error) {
+	switch network {
+	case "unix", "unixgram":
+		return &net.UnixAddr{Name: address, Net: network}, nil
+	case "tcp", "udp":
+	default:
+		return nil, ErrUnknownNetwork
+	}
+
+	host, portStr, err := net.SplitHostPort(address)
+	if err != nil {
+		return nil, err
+	}
+
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return nil, fmt.Errorf("invalid IP %q", host)
+	}
+
+	port, err := strconv.ParseUint(portStr, 10, 16)
+	if err != nil {
+		return nil, fmt.Errorf("invalid port %q", portStr)
+	}
+
+	if network == "udp" {
+		return &net.UDPAddr{IP: ip, Port: int(port)}, nil
+	}
+	return &net.TCPAddr{IP: ip, Port: int(port)}, nil
+}
+
+// TLVList is flag.Value which collect TLVs
+type TLVList []proxyprotocol.TLV
+
+// String implement flag.Value
+func (tlvs *TLVList) String() string {
+	parts := make([]string, 0, len(*tlvs))
+	for _, tlv := range *tlvs {
+		parts = append(parts, fmt.Sprintf("0x%02X=hex:%x", tlv.Type, tlv.Value))
+	}
+	return strings.Join(parts, ",")
+}
+
+// Set parse TLV in TYPE=VALUE or TYPE=hex:VALUE form. Type can be decimal or
+// hexadecimal with 0x prefix.
+func (tlvs *TLVList) Set(value string) error {
+	tlv, err := ParseTLV(value)
+	if err != nil {
+		return err
+	}
+
+	*tlvs = append(*tlvs, tlv)
+	return nil
+}
+
+// ParseTLV parse TLV in TYPE=VALUE or TYPE=hex:VALUE form
+func ParseTLV(value string) (proxyprotocol.TLV, error) {
+	parts := strings.SplitN(value, "=", 2)
+	if len(parts) != 2 {
+		return proxyprotocol.TLV{}, ErrInvalidTLV
+	}
+
+	tlvType, err := strconv.ParseUint(parts[0], 0, 8)
+	if err != nil {
+		return proxyprotocol.TLV{}, ErrInvalidTLV
+	}
+
+	tlvValue := []byte(parts[1])
+	if strings.HasPrefix(parts[1], "hex:") {
+		if tlvValue, err = hex.DecodeString(strings.TrimPrefix(parts[1], "hex:")); err != nil {
+			return proxyprotocol.TLV{}, ErrInvalidTLV
+		}
+	}
+
+	return proxyprotocol.TLV{Type: byte(tlvType), Value: tlvValue}, nil
+}
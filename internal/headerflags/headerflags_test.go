@@ -0,0 +1,95 @@
+package headerflags_test
+
+import (
+	"flag"
+	"net"
+	"reflect"
+	"testing"
+
+	"github.com/c0va23/go-proxyprotocol"
+	"github.com/c0va23/go-proxyprotocol/internal/headerflags"
+)
+
+func parseFlags(t *testing.T, args ...string) *headerflags.Flags {
+	flags := &headerflags.Flags{}
+	flagSet := flag.NewFlagSet("test", flag.ContinueOnError)
+	flags.Register(flagSet)
+	if err := flagSet.Parse(args); err != nil {
+		t.Fatal(err)
+	}
+	return flags
+}
+
+func TestFlags_Header(t *testing.T) {
+	t.Run("TCP header with TLVs", func(t *testing.T) {
+		flags := parseFlags(t,
+			"-src", "192.168.1.2:12345",
+			"-dst", "10.0.0.2:8080",
+			"-authority", "example.com",
+			"-tlv", "0xE1=hex:0102",
+			"-tlv", "226=text",
+		)
+
+		header, err := flags.Header()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		expectedHeader := &proxyprotocol.Header{
+			SrcAddr: &net.TCPAddr{IP: net.ParseIP("192.168.1.2"), Port: 12345},
+			DstAddr: &net.TCPAddr{IP: net.ParseIP("10.0.0.2"), Port: 8080},
+			Version: proxyprotocol.Version2,
+			TLVs: []proxyprotocol.TLV{
+				{Type: proxyprotocol.TLVTypeAuthority, Value: []byte("example.com")},
+				{Type: 0xE1, Value: []byte{0x01, 0x02}},
+				{Type: 0xE2, Value: []byte("text")},
+			},
+		}
+		if !reflect.DeepEqual(header, expectedHeader) {
+			t.Errorf("Unexpected header %+v", header)
+		}
+	})
+
+	t.Run("unix LOCAL header", func(t *testing.T) {
+		flags := parseFlags(t, "-network", "unixgram", "-src", "/src.sock", "-dst", "/dst.sock", "-local")
+
+		header, err := flags.Header()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if header.Command != proxyprotocol.CommandLocal ||
+			!reflect.DeepEqual(header.SrcAddr, &net.UnixAddr{Name: "/src.sock", Net: "unixgram"}) {
+			t.Errorf("Unexpected header %+v", header)
+		}
+	})
+
+	t.Run("invalid address", func(t *testing.T) {
+		flags := parseFlags(t, "-src", "192.168.1.2", "-dst", "10.0.0.2:8080")
+
+		if _, err := flags.Header(); err == nil {
+			t.Error("Expected error")
+		}
+	})
+}
+
+func TestFlags_Format(t *testing.T) {
+	flags := parseFlags(t, "-version", "1", "-src", "[::1]:1000", "-dst", "[::2]:2000")
+
+	headerBuf, err := flags.Format()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(headerBuf) != "PROXY TCP6 ::1 ::2 1000 2000\r\n" {
+		t.Errorf("Unexpected header %q", headerBuf)
+	}
+}
+
+func TestParseTLV(t *testing.T) {
+	for _, value := range []string{"0xE1", "256=value", "0xE1=hex:0"} {
+		if _, err := headerflags.ParseTLV(value); err != headerflags.ErrInvalidTLV {
+			t.Errorf("Unexpected error %v for %s", err, value)
+		}
+	}
+}
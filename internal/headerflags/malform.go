@@ -0,0 +1,132 @@
+package headerflags
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"strings"
+
+	"github.com/c0va23/go-proxyprotocol"
+)
+
+// ErrUnknownMalform returned for unknown or not applicable malform variant
+var ErrUnknownMalform = errors.New("unknown malform variant")
+
+// Malform variants
+const (
+	MalformNone       = ""
+	MalformSignature  = "signature"
+	MalformTruncate   = "truncate"
+	MalformVersion    = "version"
+	MalformCommand    = "command"
+	MalformFamily     = "family"
+	MalformOversized  = "oversized"
+	MalformUndersized = "undersized"
+	MalformChecksum   = "checksum"
+	MalformNoCRLF     = "no-crlf"
+	MalformTooLong    = "too-long"
+)
+
+// Binary header byte positions
+const (
+	malformV2CommandPos = 12
+	malformV2FamilyPos  = 13
+	malformV2LenPos     = 14
+)
+
+// MalformVariants list supported variants with description
+var MalformVariants = [][2]string{
+	{MalformSignature, "corrupt signature"},
+	{MalformTruncate, "send first half of header"},
+	{MalformVersion, "v2: unknown version"},
+	{MalformCommand, "v2: unknown command"},
+	{MalformFamily, "unknown address family (v1: TCP5)"},
+	{MalformOversized, "v2: length bigger than sent data"},
+	{MalformUndersized, "v2: length smaller than addresses"},
+	{MalformChecksum, "v2: wrong CRC32C checksum"},
+	{MalformNoCRLF, "v1: LF without CR"},
+	{MalformTooLong, "v1: line longer than 107 bytes"},
+}
+
+// MalformUsage return description of variants for flag usage
+func MalformUsage() string {
+	parts := make([]string, 0, len(MalformVariants))
+	for _, variant := range MalformVariants {
+		parts = append(parts, variant[0]+" ("+variant[1]+")")
+	}
+	return "Malformed header variant: " + strings.Join(parts, ", ")
+}
+
+// Malform return copy of encoded header broken by variant. Version detected
+// by header signature.
+func Malform(headerBuf []byte, variant string) ([]byte, error) {
+	headerBuf = append([]byte(nil), headerBuf...)
+	binaryHeader := bytes.HasPrefix(headerBuf, proxyprotocol.BinarySignature)
+
+	switch {
+	case variant == MalformNone:
+	case variant == MalformSignature:
+		headerBuf[0] ^= 0xFF
+	case variant == MalformTruncate:
+		headerBuf = headerBuf[:len(headerBuf)/2]
+	case variant == MalformVersion && binaryHeader:
+		headerBuf[malformV2CommandPos] = 0x30 | headerBuf[malformV2CommandPos]&proxyprotocol.BinaryCommandMask
+	case variant == MalformCommand && binaryHeader:
+		headerBuf[malformV2CommandPos] |= proxyprotocol.BinaryCommandMask
+	case variant == MalformFamily && binaryHeader:
+		headerBuf[malformV2FamilyPos] = 0x40 | headerBuf[malformV2FamilyPos]&proxyprotocol.BinaryTPMask
+	case variant == MalformFamily:
+		return malformTextFamily(headerBuf)
+	case variant == MalformOversized && binaryHeader:
+		binary.BigEndian.PutUint16(headerBuf[malformV2LenPos:], 0xFFFF)
+	case variant == MalformUndersized && binaryHeader:
+		binary.BigEndian.PutUint16(headerBuf[malformV2LenPos:], 1)
+	case variant == MalformChecksum && binaryHeader:
+		return malformChecksum(headerBuf)
+	case variant == MalformNoCRLF && !binaryHeader:
+		headerBuf = append(bytes.TrimSuffix(headerBuf, proxyprotocol.TextCRLF), proxyprotocol.TextLF)
+	case variant == MalformTooLong && !binaryHeader:
+		padding := bytes.Repeat([]byte{' '}, proxyprotocol.TextHeaderMaxLen)
+		headerBuf = append(bytes.TrimSuffix(headerBuf, proxyprotocol.TextCRLF), padding...)
+		headerBuf = append(headerBuf, proxyprotocol.TextCRLF...)
+	default:
+		return nil, ErrUnknownMalform
+	}
+
+	return headerBuf, nil
+}
+
+// malformTextFamily replace TCP4 or TCP6 protocol token with TCP5
+func malformTextFamily(headerBuf []byte) ([]byte, error) {
+	parts := bytes.SplitN(headerBuf, []byte(proxyprotocol.TextSeparator), 3)
+	if len(parts) < 3 {
+		return nil, ErrUnknownMalform
+	}
+
+	switch string(parts[1]) {
+	case proxyprotocol.TextProtocolIPv4, proxyprotocol.TextProtocolIPv6:
+		parts[1] = []byte("TCP5")
+	default:
+		return nil, ErrUnknownMalform
+	}
+
+	return bytes.Join(parts, []byte(proxyprotocol.TextSeparator)), nil
+}
+
+// malformChecksum flip bits of CRC32C TLV value. Header must contain it.
+func malformChecksum(headerBuf []byte) ([]byte, error) {
+	parser := proxyprotocol.NewBinaryHeaderParser(proxyprotocol.FallbackLogger{})
+	header, err := parser.Parse(bufio.NewReader(bytes.NewReader(headerBuf)))
+	if err != nil && err != proxyprotocol.ErrChecksumMismatch {
+		return nil, err
+	}
+
+	checksumPos, found := header.ChecksumValuePos()
+	if !found {
+		return nil, ErrUnknownMalform
+	}
+
+	headerBuf[checksumPos] ^= 0xFF
+	return headerBuf, nil
+}
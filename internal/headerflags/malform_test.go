@@ -0,0 +1,128 @@
+package headerflags_test
+
+import (
+	"bufio"
+	"bytes"
+	"net"
+	"testing"
+
+	"github.com/c0va23/go-proxyprotocol"
+	"github.com/c0va23/go-proxyprotocol/internal/headerflags"
+)
+
+func TestMalform(t *testing.T) {
+	logger := proxyprotocol.LoggerFunc(t.Logf)
+	parser := proxyprotocol.NewFallbackHeaderParser(
+		logger,
+		proxyprotocol.NewTextHeaderParser(logger),
+		proxyprotocol.NewBinaryHeaderParser(logger),
+	)
+
+	versionVariants := map[string][]string{
+		"1": {
+			headerflags.MalformSignature,
+			headerflags.MalformTruncate,
+			headerflags.MalformFamily,
+			headerflags.MalformTooLong,
+		},
+		"2": {
+			headerflags.MalformSignature,
+			headerflags.MalformTruncate,
+			headerflags.MalformVersion,
+			headerflags.MalformCommand,
+			headerflags.MalformFamily,
+			headerflags.MalformOversized,
+			headerflags.MalformUndersized,
+			headerflags.MalformChecksum,
+		},
+	}
+
+	for version, variants := range versionVariants {
+		for _, variant := range variants {
+			flags := parseFlags(t,
+				"-version", version,
+				"-src", "192.168.1.2:12345",
+				"-dst", "10.0.0.2:8080",
+				"-malform", variant,
+			)
+
+			headerBuf, err := flags.Format()
+			if err != nil {
+				t.Fatalf("Format v%s %s error: %s", version, variant, err)
+			}
+
+			if header, err := parser.Parse(bufio.NewReader(bytes.NewReader(headerBuf))); err == nil {
+				t.Errorf("Expected parse error for v%s %s, got %+v", version, variant, header)
+			}
+		}
+	}
+
+	t.Run("not applicable variant", func(t *testing.T) {
+		flags := parseFlags(t, "-version", "1", "-malform", headerflags.MalformChecksum)
+
+		if _, err := flags.Format(); err != headerflags.ErrUnknownMalform {
+			t.Errorf("Unexpected error %v", err)
+		}
+	})
+
+	t.Run("checksum value repeated in later TLV", func(t *testing.T) {
+		header := &proxyprotocol.Header{
+			SrcAddr: &net.TCPAddr{IP: net.IPv4(192, 168, 1, 2), Port: 12345},
+			DstAddr: &net.TCPAddr{IP: net.IPv4(10, 0, 0, 2), Port: 8080},
+			TLVs: []proxyprotocol.TLV{
+				{Type: proxyprotocol.TLVTypeCRC32C, Value: make([]byte, proxyprotocol.TLVCRC32CLen)},
+				{Type: 0xE0, Value: make([]byte, proxyprotocol.TLVCRC32CLen)},
+			},
+		}
+		headerBuf, err := header.FormatV2()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		checksumHeader, err := parser.Parse(bufio.NewReader(bytes.NewReader(headerBuf)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		crcTLV, _ := checksumHeader.FindTLV(proxyprotocol.TLVTypeCRC32C)
+		checksumValue := append([]byte(nil), crcTLV.Value...)
+
+		// Put checksum value into 0xE0 TLV
+		otherTLVPos := bytes.Index(headerBuf, []byte{0xE0, 0x00, proxyprotocol.TLVCRC32CLen}) + 3
+		copy(headerBuf[otherTLVPos:], checksumValue)
+
+		headerBuf, err = headerflags.Malform(headerBuf, headerflags.MalformChecksum)
+		if err != nil {
+			t.Fatalf("Unexpected error %s", err)
+		}
+
+		malformedHeader, _ := parser.Parse(bufio.NewReader(bytes.NewReader(headerBuf)))
+		if malformedHeader == nil {
+			t.Fatal("Header not parsed")
+		}
+		crcTLV, _ = malformedHeader.FindTLV(proxyprotocol.TLVTypeCRC32C)
+		otherTLV, _ := malformedHeader.FindTLV(0xE0)
+		if crcTLV.Value[0] != checksumValue[0]^0xFF || !bytes.Equal(otherTLV.Value, checksumValue) {
+			t.Errorf("Unexpected TLVs %+v", malformedHeader.TLVs)
+		}
+	})
+
+	t.Run("v1 family", func(t *testing.T) {
+		headerBuf, err := headerflags.Malform([]byte("PROXY TCP6 ::1 ::2 1 2\r\n"), headerflags.MalformFamily)
+		if err != nil || string(headerBuf) != "PROXY TCP5 ::1 ::2 1 2\r\n" {
+			t.Errorf("Unexpected header %q, error %v", headerBuf, err)
+		}
+	})
+
+	t.Run("v1 unknown family", func(t *testing.T) {
+		if _, err := headerflags.Malform([]byte("PROXY UNKNOWN\r\n"), headerflags.MalformFamily); err != headerflags.ErrUnknownMalform {
+			t.Errorf("Unexpected error %v", err)
+		}
+	})
+
+	t.Run("v1 without CR", func(t *testing.T) {
+		headerBuf, err := headerflags.Malform([]byte("PROXY UNKNOWN\r\n"), headerflags.MalformNoCRLF)
+		if err != nil || string(headerBuf) != "PROXY UNKNOWN\n" {
+			t.Errorf("Unexpected header %q, error %v", headerBuf, err)
+		}
+	})
+}
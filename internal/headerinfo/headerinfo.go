@@ -0,0 +1,267 @@
+// Package headerinfo describe parsed proxyprotocol header for diagnostic
+// commands in text and JSON form.
+package headerinfo
+
+import (
+	"encoding/hex"
+	"fmt"
+	"io"
+	"strconv"
+	"unicode/utf8"
+
+	"github.com/c0va23/go-proxyprotocol"
+)
+
+// Info is JSON-friendly description of proxyprotocol header
+type Info struct {
+	Version       byte      `json:"version"`
+	Command       string    `json:"command"`
+	AddressFamily string    `json:"address_family"`
+	Transport     string    `json:"transport"`
+	SrcAddr       string    `json:"src_addr,omitempty"`
+	DstAddr       string    `json:"dst_addr,omitempty"`
+	TLVs          []TLVInfo `json:"tlvs,omitempty"`
+	TLS           *TLSInfo  `json:"tls,omitempty"`
+	Raw           string    `json:"raw,omitempty"`
+}
+
+// TLVInfo describe TLV. Value of known types decoded into Decoded or Error.
+type TLVInfo struct {
+	Type    byte   `json:"type"`
+	Name    string `json:"name"`
+	Length  int    `json:"length"`
+	Value   string `json:"value"`
+	Decoded string `json:"decoded,omitempty"`
+	Error   string `json:"error,omitempty"`
+}
+
+// TLSInfo describe PP2_TYPE_SSL TLV
+type TLSInfo struct {
+	Client         byte   `json:"client"`
+	ClientSSL      bool   `json:"client_ssl"`
+	ClientCertConn bool   `json:"client_cert_conn"`
+	ClientCertSess bool   `json:"client_cert_sess"`
+	Verify         uint32 `json:"verify"`
+	Version        string `json:"version,omitempty"`
+	CN             string `json:"cn,omitempty"`
+	Cipher         string `json:"cipher,omitempty"`
+	SigAlg         string `json:"sig_alg,omitempty"`
+	KeyAlg         string `json:"key_alg,omitempty"`
+}
+
+var addressFamilyNames = map[byte]string{
+	proxyprotocol.BinaryAFUnspec: "UNSPEC",
+	proxyprotocol.BinaryAFInet:   "INET",
+	proxyprotocol.BinaryAFInet6:  "INET6",
+	proxyprotocol.BinaryAFUnix:   "UNIX",
+}
+
+var transportNames = map[byte]string{
+	proxyprotocol.BinaryTPUnspec: "UNSPEC",
+	proxyprotocol.BinaryTPStream: "STREAM",
+	proxyprotocol.BinaryTPDgram:  "DGRAM",
+}
+
+var tlvTypeNames = map[byte]string{
+	proxyprotocol.TLVTypeALPN:      "ALPN",
+	proxyprotocol.TLVTypeAuthority: "AUTHORITY",
+	proxyprotocol.TLVTypeCRC32C:    "CRC32C",
+	proxyprotocol.TLVTypeNoop:      "NOOP",
+	proxyprotocol.TLVTypeUniqueID:  "UNIQUE_ID",
+	proxyprotocol.TLVTypeSSL:       "SSL",
+	proxyprotocol.TLVTypeNetNS:     "NETNS",
+	proxyprotocol.TLVTypeGCP:       "GCP",
+	proxyprotocol.TLVTypeAWS:       "AWS",
+	proxyprotocol.TLVTypeAzure:     "AZURE",
+}
+
+// AddressFamilyName return name of BinaryAF* constant
+func AddressFamilyName(addressFamily byte) string {
+	return nameOrNumber(addressFamilyNames, addressFamily)
+}
+
+// TransportName return name of BinaryTP* constant
+func TransportName(transport byte) string {
+	return nameOrNumber(transportNames, transport)
+}
+
+// TLVTypeName return name of TLV type
+func TLVTypeName(tlvType byte) string {
+	return nameOrNumber(tlvTypeNames, tlvType)
+}
+
+func nameOrNumber(names map[byte]string, value byte) string {
+	if name, found := names[value]; found {
+		return name
+	}
+	return fmt.Sprintf("0x%02X", value)
+}
+
+// New describe header
+func New(header *proxyprotocol.Header) Info {
+	info := Info{
+		Version:       header.Version,
+		Command:       header.Command.String(),
+		AddressFamily: AddressFamilyName(header.AddressFamily),
+		Transport:     TransportName(header.Transport),
+		Raw:           hex.EncodeToString(header.Raw),
+	}
+
+	if header.SrcAddr != nil {
+		info.SrcAddr = header.SrcAddr.String()
+	}
+	if header.DstAddr != nil {
+		info.DstAddr = header.DstAddr.String()
+	}
+
+	for _, tlv := range header.TLVs {
+		info.TLVs = append(info.TLVs, newTLVInfo(tlv))
+	}
+
+	if tlsInfo := header.TLS; tlsInfo != nil {
+		info.TLS = &TLSInfo{
+			Client:         tlsInfo.Client,
+			ClientSSL:      tlsInfo.ClientSSL(),
+			ClientCertConn: tlsInfo.ClientCertConn(),
+			ClientCertSess: tlsInfo.ClientCertSess(),
+			Verify:         tlsInfo.Verify,
+			Version:        tlsInfo.Version,
+			CN:             tlsInfo.CN,
+			Cipher:         tlsInfo.Cipher,
+			SigAlg:         tlsInfo.SigAlg,
+			KeyAlg:         tlsInfo.KeyAlg,
+		}
+	}
+
+	return info
+}
+
+func newTLVInfo(tlv proxyprotocol.TLV) TLVInfo {
+	tlvInfo := TLVInfo{
+		Type:   tlv.Type,
+		Name:   TLVTypeName(tlv.Type),
+		Length: len(tlv.Value),
+		Value:  hex.EncodeToString(tlv.Value),
+	}
+
+	decoded, err := decodeTLV(tlv)
+	if err != nil {
+		tlvInfo.Error = err.Error()
+	} else {
+		tlvInfo.Decoded = decoded
+	}
+
+	return tlvInfo
+}
+
+// decodeTLV decode value of known TLV types with header accessors
+func decodeTLV(tlv proxyprotocol.TLV) (string, error) {
+	header := &proxyprotocol.Header{TLVs: []proxyprotocol.TLV{tlv}}
+
+	switch tlv.Type {
+	case proxyprotocol.TLVTypeALPN:
+		alpn, err := header.ALPN()
+		return printable(alpn), err
+	case proxyprotocol.TLVTypeAuthority:
+		return header.Authority()
+	case proxyprotocol.TLVTypeUniqueID:
+		uniqueID, err := header.UniqueID()
+		return printable(uniqueID), err
+	case proxyprotocol.TLVTypeNetNS:
+		return header.NetNS()
+	case proxyprotocol.TLVTypeAWS:
+		return header.AWSVPCEndpointID()
+	case proxyprotocol.TLVTypeAzure:
+		linkID, err := header.AzureLinkID()
+		return strconv.FormatUint(uint64(linkID), 10), err
+	case proxyprotocol.TLVTypeGCP:
+		connectionID, err := header.GCPPSCConnectionID()
+		return strconv.FormatUint(connectionID, 10), err
+	default:
+		return "", nil
+	}
+}
+
+// printable return value as string when it is valid UTF-8 text, otherwise
+// empty string
+func printable(value []byte) string {
+	if !utf8.Valid(value) {
+		return ""
+	}
+	for _, char := range string(value) {
+		if char < ' ' || char == 0x7F {
+			return ""
+		}
+	}
+	return string(value)
+}
+
+// WriteText write info as aligned "Name: value" lines
+func (info Info) WriteText(writer io.Writer) error {
+	return WriteLines(writer, info.Lines())
+}
+
+// Lines return info as "Name: value" pairs
+func (info Info) Lines() [][2]string {
+	lines := [][2]string{
+		{"Version", strconv.Itoa(int(info.Version))},
+		{"Command", info.Command},
+		{"Address family", info.AddressFamily},
+		{"Transport", info.Transport},
+	}
+	if info.SrcAddr != "" || info.DstAddr != "" {
+		lines = append(lines,
+			[2]string{"Source", info.SrcAddr},
+			[2]string{"Destination", info.DstAddr},
+		)
+	}
+
+	for _, tlv := range info.TLVs {
+		value := fmt.Sprintf("%s (%d bytes) %s", tlv.Name, tlv.Length, tlv.Value)
+		if tlv.Decoded != "" {
+			value += fmt.Sprintf(" %q", tlv.Decoded)
+		}
+		if tlv.Error != "" {
+			value += " error: " + tlv.Error
+		}
+		lines = append(lines, [2]string{fmt.Sprintf("TLV 0x%02X", tlv.Type), value})
+	}
+
+	if tlsInfo := info.TLS; tlsInfo != nil {
+		lines = append(lines,
+			[2]string{"TLS client", fmt.Sprintf(
+				"0x%02X (ssl: %t, cert conn: %t, cert sess: %t)",
+				tlsInfo.Client, tlsInfo.ClientSSL, tlsInfo.ClientCertConn, tlsInfo.ClientCertSess,
+			)},
+			[2]string{"TLS verify", strconv.FormatUint(uint64(tlsInfo.Verify), 10)},
+			[2]string{"TLS version", tlsInfo.Version},
+			[2]string{"TLS CN", tlsInfo.CN},
+			[2]string{"TLS cipher", tlsInfo.Cipher},
+			[2]string{"TLS sig alg", tlsInfo.SigAlg},
+			[2]string{"TLS key alg", tlsInfo.KeyAlg},
+		)
+	}
+
+	if info.Raw != "" {
+		lines = append(lines, [2]string{"Raw", info.Raw})
+	}
+
+	return lines
+}
+
+// WriteLines write "Name: value" lines with aligned values
+func WriteLines(writer io.Writer, lines [][2]string) error {
+	nameWidth := 0
+	for _, line := range lines {
+		if len(line[0]) > nameWidth {
+			nameWidth = len(line[0])
+		}
+	}
+
+	for _, line := range lines {
+		if _, err := fmt.Fprintf(writer, "%-*s %s\n", nameWidth+1, line[0]+":", line[1]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,61 @@
+package headerinfo_test
+
+import (
+	"bytes"
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/c0va23/go-proxyprotocol"
+	"github.com/c0va23/go-proxyprotocol/internal/headerinfo"
+)
+
+func TestNew(t *testing.T) {
+	header := &proxyprotocol.Header{
+		SrcAddr:       &net.TCPAddr{IP: net.IPv4(192, 168, 1, 2), Port: 12345},
+		DstAddr:       &net.TCPAddr{IP: net.IPv4(10, 0, 0, 2), Port: 8080},
+		Version:       proxyprotocol.Version2,
+		AddressFamily: proxyprotocol.BinaryAFInet,
+		Transport:     proxyprotocol.BinaryTPStream,
+		TLVs: []proxyprotocol.TLV{
+			{Type: proxyprotocol.TLVTypeAuthority, Value: []byte("example.com")},
+			{Type: proxyprotocol.TLVTypeUniqueID, Value: make([]byte, proxyprotocol.TLVUniqueIDMaxLen+1)},
+			{Type: 0xE1, Value: []byte{0x01}},
+		},
+		TLS: &proxyprotocol.TLSInfo{
+			Client: proxyprotocol.TLVClientSSL,
+			CN:     "client",
+		},
+	}
+
+	info := headerinfo.New(header)
+
+	if info.Command != "PROXY" || info.AddressFamily != "INET" || info.Transport != "STREAM" ||
+		info.SrcAddr != "192.168.1.2:12345" || info.DstAddr != "10.0.0.2:8080" {
+		t.Errorf("Unexpected info %+v", info)
+	}
+
+	expectedTLVs := []headerinfo.TLVInfo{
+		{Type: proxyprotocol.TLVTypeAuthority, Name: "AUTHORITY", Length: 11, Value: "6578616d706c652e636f6d", Decoded: "example.com"},
+		{Type: proxyprotocol.TLVTypeUniqueID, Name: "UNIQUE_ID", Length: 129, Error: proxyprotocol.ErrInvalidTLVValue.Error()},
+		{Type: 0xE1, Name: "0xE1", Length: 1, Value: "01"},
+	}
+	expectedTLVs[1].Value = strings.Repeat("00", 129)
+	for i, tlv := range info.TLVs {
+		if tlv != expectedTLVs[i] {
+			t.Errorf("Unexpected TLV %+v", tlv)
+		}
+	}
+
+	if info.TLS == nil || !info.TLS.ClientSSL || info.TLS.CN != "client" {
+		t.Errorf("Unexpected TLS info %+v", info.TLS)
+	}
+
+	text := bytes.Buffer{}
+	if err := info.WriteText(&text); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(text.String(), "Source:         192.168.1.2:12345\n") {
+		t.Errorf("Unexpected text %s", text.String())
+	}
+}
@@ -0,0 +1,99 @@
+// Package sniff detect protocol of connection by first client bytes.
+package sniff
+
+import (
+	"bufio"
+	"net"
+	"time"
+
+	"github.com/c0va23/go-proxyprotocol"
+)
+
+// httpMethods recognized in first client bytes
+var httpMethods = map[string]bool{
+	"GET":     true,
+	"HEAD":    true,
+	"POST":    true,
+	"PUT":     true,
+	"DELETE":  true,
+	"CONNECT": true,
+	"OPTIONS": true,
+	"TRACE":   true,
+	"PATCH":   true,
+	"PRI":     true,
+}
+
+const maxHTTPMethodLen = 7
+
+// Conn read connection through buffer used for traffic detection. Peeked
+// bytes returned by following Read calls.
+type Conn struct {
+	net.Conn
+	reader *bufio.Reader
+}
+
+// NewConn wrap conn into Conn
+func NewConn(conn net.Conn) *Conn {
+	return &Conn{
+		Conn:   conn,
+		reader: bufio.NewReader(conn),
+	}
+}
+
+func (conn *Conn) Read(buf []byte) (int, error) {
+	return conn.reader.Read(buf)
+}
+
+// Unwrap return wrapped connection
+func (conn *Conn) Unwrap() net.Conn {
+	return conn.Conn
+}
+
+// CloseWrite close write side of raw TCP connection. Connection without
+// CloseWrite closed entirely.
+func (conn *Conn) CloseWrite() error {
+	rawConn := conn.Conn
+	if ppConn, ok := rawConn.(*proxyprotocol.Conn); ok {
+		rawConn = ppConn.Conn
+	}
+
+	if tcpConn, ok := rawConn.(*net.TCPConn); ok {
+		return tcpConn.CloseWrite()
+	}
+	return conn.Conn.Close()
+}
+
+// DetectHTTP return true when connection start with HTTP method. Connection
+// without client bytes in timeout (server speak first) is not HTTP.
+func (conn *Conn) DetectHTTP(timeout time.Duration) (bool, error) {
+	if err := conn.SetReadDeadline(time.Now().Add(timeout)); err != nil {
+		return false, err
+	}
+
+	isHTTP, err := conn.peekHTTPMethod()
+	if netErr, ok := err.(net.Error); ok && netErr.Timeout() {
+		isHTTP, err = false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	return isHTTP, conn.SetReadDeadline(time.Time{})
+}
+
+func (conn *Conn) peekHTTPMethod() (bool, error) {
+	for n := 1; n <= maxHTTPMethodLen+1; n++ {
+		buf, err := conn.reader.Peek(n)
+		if err != nil {
+			return false, err
+		}
+
+		switch char := buf[n-1]; {
+		case char == ' ':
+			return httpMethods[string(buf[:n-1])], nil
+		case char < 'A' || char > 'Z':
+			return false, nil
+		}
+	}
+	return false, nil
+}
@@ -0,0 +1,59 @@
+package sniff_test
+
+import (
+	"io/ioutil"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/c0va23/go-proxyprotocol/internal/sniff"
+)
+
+func TestConn_DetectHTTP(t *testing.T) {
+	testCases := []struct {
+		name   string
+		data   string
+		isHTTP bool
+	}{
+		{"HTTP request", "GET / HTTP/1.1\r\n\r\n", true},
+		{"HTTP/2 preface", "PRI * HTTP/2.0\r\n\r\n", true},
+		{"unknown method", "HELLO world\n", false},
+		{"binary data", "SSH-2.0-OpenSSH\r\n", false},
+		{"server speak first", "", false},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			serverConn, clientConn := net.Pipe()
+			defer serverConn.Close()
+
+			go func() {
+				if testCase.data != "" {
+					clientConn.Write([]byte(testCase.data)) // nolint: errcheck
+				}
+			}()
+
+			conn := sniff.NewConn(serverConn)
+			isHTTP, err := conn.DetectHTTP(50 * time.Millisecond)
+			if err != nil || isHTTP != testCase.isHTTP {
+				t.Fatalf("Unexpected result %t, %v", isHTTP, err)
+			}
+			clientConn.Close()
+
+			data, err := ioutil.ReadAll(conn)
+			if err != nil || string(data) != testCase.data {
+				t.Errorf("Unexpected data %q, error %v", data, err)
+			}
+		})
+	}
+}
+
+func TestConn_Unwrap(t *testing.T) {
+	serverConn, clientConn := net.Pipe()
+	defer serverConn.Close()
+	defer clientConn.Close()
+
+	if conn := sniff.NewConn(serverConn); conn.Unwrap() != serverConn {
+		t.Errorf("Unexpected conn %v", conn.Unwrap())
+	}
+}
@@ -0,0 +1,41 @@
+// Package sourcecheck build proxyprotocol.SourceChecker for diagnostic and
+// proxy commands.
+package sourcecheck
+
+import (
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/c0va23/go-proxyprotocol"
+)
+
+// ParseCIDRs parse comma-separated list of CIDRs into SourceChecker, which
+// trust TCP sources from these networks. Empty list trust all sources.
+func ParseCIDRs(list string) (proxyprotocol.SourceChecker, error) {
+	if list == "" {
+		return func(net.Addr) (bool, error) { return true, nil }, nil
+	}
+
+	var networks []*net.IPNet
+	for _, cidr := range strings.Split(list, ",") {
+		_, network, err := net.ParseCIDR(strings.TrimSpace(cidr))
+		if err != nil {
+			return nil, err
+		}
+		networks = append(networks, network)
+	}
+
+	return func(addr net.Addr) (bool, error) {
+		tcpAddr, ok := addr.(*net.TCPAddr)
+		if !ok {
+			return false, fmt.Errorf("unexpected address %s", addr)
+		}
+		for _, network := range networks {
+			if network.Contains(tcpAddr.IP) {
+				return true, nil
+			}
+		}
+		return false, nil
+	}, nil
+}
@@ -0,0 +1,52 @@
+package sourcecheck_test
+
+import (
+	"net"
+	"testing"
+
+	"github.com/c0va23/go-proxyprotocol/internal/sourcecheck"
+)
+
+func TestParseCIDRs(t *testing.T) {
+	t.Run("empty", func(t *testing.T) {
+		checker, err := sourcecheck.ParseCIDRs("")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if trusted, err := checker(&net.TCPAddr{IP: net.IPv4(192, 0, 2, 1)}); !trusted || err != nil {
+			t.Errorf("Unexpected result %t, %v", trusted, err)
+		}
+	})
+
+	t.Run("list", func(t *testing.T) {
+		checker, err := sourcecheck.ParseCIDRs("10.0.0.0/8, 2001:db8::/32")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		testCases := []struct {
+			ip      string
+			trusted bool
+		}{
+			{"10.1.2.3", true},
+			{"2001:db8::1", true},
+			{"192.0.2.1", false},
+		}
+		for _, testCase := range testCases {
+			trusted, err := checker(&net.TCPAddr{IP: net.ParseIP(testCase.ip)})
+			if err != nil || trusted != testCase.trusted {
+				t.Errorf("Unexpected result for %s: %t, %v", testCase.ip, trusted, err)
+			}
+		}
+
+		if _, err := checker(&net.UnixAddr{Name: "/tmp/echo.sock"}); err == nil {
+			t.Error("Expected error for unix address")
+		}
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		if _, err := sourcecheck.ParseCIDRs("10.0.0.0"); err == nil {
+			t.Error("Expected error")
+		}
+	})
+}
@@ -23,6 +23,7 @@ type Listener struct {
 	Logger
 	HeaderParserBuilder
 	SourceChecker
+	ChecksumPolicy ChecksumPolicy
 }
 
 // WithLogger copy Listener and set Logger
@@ -47,6 +48,14 @@ func (listener Listener) WithSourceChecker(sourceChecker SourceChecker) Listener
 	return newListener
 }
 
+// WithChecksumPolicy copy Listener and set ChecksumPolicy.
+// By default headers with mismatched checksum rejected.
+func (listener Listener) WithChecksumPolicy(checksumPolicy ChecksumPolicy) Listener {
+	newListener := listener
+	newListener.ChecksumPolicy = checksumPolicy
+	return newListener
+}
+
 // Accept implement net.Listener.Accept().
 //
 // When listener have SourceChecker, then check source address.
@@ -77,6 +86,13 @@ func (listener Listener) Accept() (net.Conn, error) {
 	}
 
 	headerParser := listener.HeaderParserBuilder.Build(logger)
+	if listener.ChecksumPolicy != ChecksumPolicyEnforce {
+		headerParser = checksumPolicyHeaderParser{
+			HeaderParser: headerParser,
+			logger:       logger,
+			policy:       listener.ChecksumPolicy,
+		}
+	}
 
 	return NewConn(rawConn, logger, headerParser, trusted), nil
 }
@@ -0,0 +1,292 @@
+package pcapanalyzer
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"net"
+	"time"
+
+	"github.com/c0va23/go-proxyprotocol"
+)
+
+// Status of connection header
+type Status int
+
+// Connection statuses
+const (
+	// StatusHeader mean that header decoded
+	StatusHeader Status = iota
+	// StatusNoHeader mean that connection not start with header signature
+	StatusNoHeader
+	// StatusMalformed mean that header signature found, but header invalid
+	StatusMalformed
+	// StatusTruncated mean that capture end before header complete
+	StatusTruncated
+	// StatusNoData mean that data of connection initiator not captured
+	StatusNoData
+)
+
+// String return status name
+func (status Status) String() string {
+	switch status {
+	case StatusHeader:
+		return "header"
+	case StatusNoHeader:
+		return "no header"
+	case StatusMalformed:
+		return "malformed"
+	case StatusTruncated:
+		return "truncated"
+	case StatusNoData:
+		return "no data"
+	default:
+		return "unknown"
+	}
+}
+
+// maxHeaderLen is maximal length of binary header
+var maxHeaderLen = proxyprotocol.BinarySignatureLen + 4 + 1<<16 - 1
+
+// maxPendingSegments limit out of order segments stored per stream
+const maxPendingSegments = 64
+
+// Connection is report of TCP connection
+type Connection struct {
+	// SrcAddr is address of connection initiator. For connections from
+	// load balancer it is load balancer address.
+	SrcAddr *net.TCPAddr
+	DstAddr *net.TCPAddr
+	// FirstSeen is timestamp of first captured segment
+	FirstSeen time.Time
+	// Handshake is true when SYN of connection captured. Otherwise connection
+	// initiator is sender of first captured data.
+	Handshake bool
+	Status    Status
+	// Header is decoded header. It set together with Err when checksum
+	// mismatch.
+	Header *proxyprotocol.Header
+	Err    error
+}
+
+// stream reassemble first bytes sent by connection initiator
+type stream struct {
+	connection *Connection
+	synSeq     uint32
+	nextSeq    uint32
+	data       []byte
+	pending    map[uint32][]byte
+	done       bool
+}
+
+// Analyzer collect connections from captured packets
+type Analyzer struct {
+	logger      proxyprotocol.Logger
+	streams     map[string]*stream
+	connections []*Connection
+}
+
+// NewAnalyzer construct Analyzer. Logger used by header parsers and can be nil.
+func NewAnalyzer(logger proxyprotocol.Logger) *Analyzer {
+	return &Analyzer{
+		logger:  proxyprotocol.FallbackLogger{Logger: logger},
+		streams: make(map[string]*stream),
+	}
+}
+
+// Analyze read all packets of capture and return connections in order of
+// first packet. Connections collected before read error returned with error.
+func Analyze(reader io.Reader, logger proxyprotocol.Logger) ([]*Connection, error) {
+	packetReader, err := NewPacketReader(reader)
+	if err != nil {
+		return nil, err
+	}
+
+	analyzer := NewAnalyzer(logger)
+	for {
+		packet, err := packetReader.ReadPacket()
+		if err == io.EOF {
+			return analyzer.Connections(), nil
+		}
+		if err != nil {
+			return analyzer.Connections(), err
+		}
+
+		analyzer.AddPacket(packet)
+	}
+}
+
+// AddPacket add packet into connection stream. Not TCP packets ignored.
+func (analyzer *Analyzer) AddPacket(packet Packet) {
+	segment, ok := DecodeSegment(packet)
+	if !ok {
+		return
+	}
+
+	key := streamKey(segment.SrcAddr, segment.DstAddr)
+	stream := analyzer.streams[key]
+	seq := segment.Seq
+
+	if segment.Flags&TCPFlagSYN != 0 {
+		if segment.Flags&TCPFlagACK != 0 {
+			return
+		}
+		if stream == nil || stream.synSeq != seq || !stream.connection.Handshake {
+			stream = analyzer.newStream(key, segment, packet.Timestamp)
+			stream.connection.Handshake = true
+			stream.synSeq = seq
+		}
+		seq++
+	}
+
+	if stream == nil {
+		_, reverseFound := analyzer.streams[streamKey(segment.DstAddr, segment.SrcAddr)]
+		if reverseFound || len(segment.Payload) == 0 {
+			return
+		}
+		stream = analyzer.newStream(key, segment, packet.Timestamp)
+		stream.nextSeq = seq
+	}
+
+	if stream.done || len(segment.Payload) == 0 {
+		return
+	}
+
+	stream.addPayload(seq, segment.Payload)
+	stream.parseHeader(analyzer.logger)
+}
+
+func (analyzer *Analyzer) newStream(key string, segment Segment, timestamp time.Time) *stream {
+	if prevStream, found := analyzer.streams[key]; found {
+		prevStream.finish()
+	}
+
+	stream := &stream{
+		connection: &Connection{
+			SrcAddr:   segment.SrcAddr,
+			DstAddr:   segment.DstAddr,
+			FirstSeen: timestamp,
+		},
+		nextSeq: segment.Seq + 1,
+		pending: make(map[uint32][]byte),
+	}
+	analyzer.streams[key] = stream
+	analyzer.connections = append(analyzer.connections, stream.connection)
+
+	return stream
+}
+
+// Connections finish incomplete streams and return all connections
+func (analyzer *Analyzer) Connections() []*Connection {
+	for _, stream := range analyzer.streams {
+		stream.finish()
+	}
+	return analyzer.connections
+}
+
+func streamKey(srcAddr, dstAddr *net.TCPAddr) string {
+	return srcAddr.String() + ">" + dstAddr.String()
+}
+
+// addPayload append in-order data and store out of order segments
+func (stream *stream) addPayload(seq uint32, payload []byte) {
+	if offset := int32(seq - stream.nextSeq); offset > 0 {
+		if len(stream.pending) < maxPendingSegments {
+			stream.pending[seq] = append([]byte(nil), payload...)
+		}
+		return
+	}
+
+	stream.appendData(seq, payload)
+
+	for found := true; found; {
+		found = false
+		for pendingSeq, pendingPayload := range stream.pending {
+			if int32(pendingSeq-stream.nextSeq) <= 0 {
+				delete(stream.pending, pendingSeq)
+				stream.appendData(pendingSeq, pendingPayload)
+				found = true
+			}
+		}
+	}
+}
+
+// appendData append part of payload after already received data
+func (stream *stream) appendData(seq uint32, payload []byte) {
+	overlap := int(stream.nextSeq - seq)
+	if overlap >= len(payload) {
+		return
+	}
+	payload = payload[overlap:]
+
+	if rest := maxHeaderLen - len(stream.data); len(payload) > rest {
+		payload = payload[:rest]
+	}
+
+	stream.data = append(stream.data, payload...)
+	stream.nextSeq += uint32(len(payload))
+}
+
+// parseHeader try decode header from received data. Stream done when header
+// decoded or data is not valid header.
+func (stream *stream) parseHeader(logger proxyprotocol.Logger) {
+	parser := proxyprotocol.NewFallbackHeaderParser(
+		logger,
+		proxyprotocol.NewTextHeaderParser(logger),
+		proxyprotocol.NewBinaryHeaderParser(logger),
+	)
+	header, err := parser.Parse(bufio.NewReader(bytes.NewReader(stream.data)))
+
+	connection := stream.connection
+	switch err {
+	case nil:
+		connection.Status = StatusHeader
+	case proxyprotocol.ErrInvalidHeader:
+		connection.Status = StatusNoHeader
+	case io.EOF, io.ErrUnexpectedEOF:
+		if !bytes.HasPrefix(stream.data, proxyprotocol.TextSignature) || len(stream.data) < proxyprotocol.TextHeaderMaxLen {
+			return
+		}
+		connection.Status, err = StatusMalformed, proxyprotocol.ErrHeaderTooLong
+	default:
+		connection.Status = StatusMalformed
+	}
+
+	connection.Header, connection.Err = header, err
+	stream.done = true
+	stream.data, stream.pending = nil, nil
+}
+
+// finish set status of stream which not done
+func (stream *stream) finish() {
+	if stream.done {
+		return
+	}
+
+	switch {
+	case len(stream.data) == 0:
+		stream.connection.Status = StatusNoData
+	case !signaturePrefix(stream.data):
+		stream.connection.Status = StatusNoHeader
+	default:
+		stream.connection.Status = StatusTruncated
+		stream.connection.Err = io.ErrUnexpectedEOF
+	}
+
+	stream.done = true
+	stream.data, stream.pending = nil, nil
+}
+
+// signaturePrefix return true when data can be beginning of header signature
+func signaturePrefix(data []byte) bool {
+	for _, signature := range [][]byte{proxyprotocol.TextSignature, proxyprotocol.BinarySignature} {
+		prefixLen := len(data)
+		if prefixLen > len(signature) {
+			prefixLen = len(signature)
+		}
+		if bytes.Equal(data[:prefixLen], signature[:prefixLen]) {
+			return true
+		}
+	}
+	return false
+}
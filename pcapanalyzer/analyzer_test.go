@@ -0,0 +1,179 @@
+package pcapanalyzer_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"net"
+	"reflect"
+	"testing"
+
+	"github.com/c0va23/go-proxyprotocol"
+	"github.com/c0va23/go-proxyprotocol/pcapanalyzer"
+)
+
+func analyzeFrames(t *testing.T, frames ...[]byte) []*pcapanalyzer.Connection {
+	capture := buildPcap(pcapanalyzer.LinkTypeEthernet, frames...)
+	connections, err := pcapanalyzer.Analyze(bytes.NewReader(capture), proxyprotocol.LoggerFunc(t.Logf))
+	if err != nil {
+		t.Fatalf("Unexpected error %s", err)
+	}
+	return connections
+}
+
+func TestAnalyze(t *testing.T) {
+	clientAddr := &net.TCPAddr{IP: net.IPv4(192, 168, 1, 2), Port: 12345}
+	header := &proxyprotocol.Header{
+		SrcAddr: clientAddr,
+		DstAddr: testSrvAddr,
+		TLVs:    []proxyprotocol.TLV{{Type: proxyprotocol.TLVTypeAuthority, Value: []byte("example.com")}},
+	}
+
+	syn := buildIPv4Frame(testSegment{src: testLBAddr, dst: testSrvAddr, seq: 1000, flags: pcapanalyzer.TCPFlagSYN})
+	synAck := buildIPv4Frame(testSegment{
+		src: testSrvAddr, dst: testLBAddr, seq: 5000,
+		flags: pcapanalyzer.TCPFlagSYN | pcapanalyzer.TCPFlagACK,
+	})
+	clientData := func(seq uint32, payload []byte) []byte {
+		return buildIPv4Frame(testSegment{
+			src: testLBAddr, dst: testSrvAddr, seq: seq,
+			flags: pcapanalyzer.TCPFlagACK, payload: payload,
+		})
+	}
+
+	t.Run("v1 header in out of order segments", func(t *testing.T) {
+		headerBuf, err := header.FormatV1()
+		if err != nil {
+			t.Fatal(err)
+		}
+		payload := append(headerBuf, "GET / HTTP/1.0\r\n\r\n"...)
+
+		connections := analyzeFrames(t,
+			syn,
+			synAck,
+			clientData(1011, payload[10:]),
+			clientData(1001, payload[:10]),
+			clientData(1001, payload[:10]),
+		)
+
+		if len(connections) != 1 {
+			t.Fatalf("Unexpected connections %d", len(connections))
+		}
+		connection := connections[0]
+
+		if connection.Status != pcapanalyzer.StatusHeader || connection.Err != nil || !connection.Handshake {
+			t.Fatalf("Unexpected connection %+v", connection)
+		}
+		if !reflect.DeepEqual(connection.SrcAddr, testLBAddr) || !connection.FirstSeen.Equal(testTimestamp.Truncate(1000)) {
+			t.Errorf("Unexpected connection %+v", connection)
+		}
+		if connection.Header.SrcAddr.String() != clientAddr.String() || connection.Header.Version != proxyprotocol.Version1 {
+			t.Errorf("Unexpected header %+v", connection.Header)
+		}
+	})
+
+	t.Run("v1 header in segment with zero IPv4 total length", func(t *testing.T) {
+		headerBuf, err := header.FormatV1()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// TCP segmentation offload: captured on sending host before
+		// segmentation, total length not filled
+		tsoFrame := clientData(1001, append(headerBuf, "GET / HTTP/1.0\r\n\r\n"...))
+		binary.BigEndian.PutUint16(tsoFrame[14+2:14+4], 0)
+
+		connections := analyzeFrames(t, syn, synAck, tsoFrame)
+		if len(connections) != 1 || connections[0].Status != pcapanalyzer.StatusHeader {
+			t.Fatalf("Unexpected connections %+v", connections)
+		}
+		if connections[0].Header.SrcAddr.String() != clientAddr.String() {
+			t.Errorf("Unexpected header %+v", connections[0].Header)
+		}
+	})
+
+	t.Run("v2 header in pcapng with raw IPv6 packets", func(t *testing.T) {
+		lbAddr := &net.TCPAddr{IP: net.ParseIP("fd00::1"), Port: 40000}
+		srvAddr := &net.TCPAddr{IP: net.ParseIP("fd00::2"), Port: 8080}
+
+		headerBuf, err := header.FormatV2()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		capture := buildPcapng(binary.LittleEndian, uint16(pcapanalyzer.LinkTypeRaw),
+			buildIPv6Packet(testSegment{src: lbAddr, dst: srvAddr, seq: 1, flags: pcapanalyzer.TCPFlagSYN}),
+			buildIPv6Packet(testSegment{src: lbAddr, dst: srvAddr, seq: 2, flags: pcapanalyzer.TCPFlagACK, payload: headerBuf}),
+		)
+		connections, err := pcapanalyzer.Analyze(bytes.NewReader(capture), nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if len(connections) != 1 || connections[0].Status != pcapanalyzer.StatusHeader {
+			t.Fatalf("Unexpected connections %+v", connections)
+		}
+		if authority, err := connections[0].Header.Authority(); err != nil || authority != "example.com" {
+			t.Errorf("Unexpected authority %s, error %v", authority, err)
+		}
+		if !connections[0].SrcAddr.IP.Equal(lbAddr.IP) {
+			t.Errorf("Unexpected LB addr %s", connections[0].SrcAddr)
+		}
+	})
+
+	t.Run("connection statuses", func(t *testing.T) {
+		headerBuf, err := header.FormatV2()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		testCases := []struct {
+			name    string
+			payload []byte
+			status  pcapanalyzer.Status
+			err     error
+		}{
+			{"no header", []byte("GET / HTTP/1.0\r\n\r\n"), pcapanalyzer.StatusNoHeader, proxyprotocol.ErrInvalidHeader},
+			{"short not header", []byte("GET"), pcapanalyzer.StatusNoHeader, nil},
+			{"malformed", []byte("PROXY TCP4 bad\r\n"), pcapanalyzer.StatusMalformed, proxyprotocol.ErrInvalidAddressList},
+			{"too long text", append([]byte("PROXY "), make([]byte, 200)...), pcapanalyzer.StatusMalformed, proxyprotocol.ErrHeaderTooLong},
+			{"truncated", headerBuf[:20], pcapanalyzer.StatusTruncated, io.ErrUnexpectedEOF},
+			{"no data", nil, pcapanalyzer.StatusNoData, nil},
+		}
+
+		for _, testCase := range testCases {
+			t.Run(testCase.name, func(t *testing.T) {
+				frames := [][]byte{syn, synAck}
+				if testCase.payload != nil {
+					frames = append(frames, clientData(1001, testCase.payload))
+				}
+
+				connections := analyzeFrames(t, frames...)
+				if len(connections) != 1 {
+					t.Fatalf("Unexpected connections %d", len(connections))
+				}
+
+				if connections[0].Status != testCase.status || connections[0].Err != testCase.err {
+					t.Errorf("Unexpected status %s, error %v", connections[0].Status, connections[0].Err)
+				}
+			})
+		}
+	})
+
+	t.Run("connection without handshake and reused tuple", func(t *testing.T) {
+		connections := analyzeFrames(t,
+			clientData(7000, []byte("PROXY UNKNOWN\r\n")),
+			syn,
+			clientData(1001, []byte("PROXY UNKNOWN\r\n")),
+		)
+
+		if len(connections) != 2 || connections[0].Handshake || !connections[1].Handshake {
+			t.Fatalf("Unexpected connections %+v", connections)
+		}
+		for _, connection := range connections {
+			if connection.Status != pcapanalyzer.StatusHeader {
+				t.Errorf("Unexpected connection %+v", connection)
+			}
+		}
+	})
+}
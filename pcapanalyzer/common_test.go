@@ -0,0 +1,137 @@
+package pcapanalyzer_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"net"
+	"time"
+)
+
+var (
+	testTimestamp = time.Date(2019, 5, 1, 10, 0, 0, 123456000, time.UTC)
+	testLBAddr    = &net.TCPAddr{IP: net.IPv4(10, 0, 0, 1).To4(), Port: 40000}
+	testSrvAddr   = &net.TCPAddr{IP: net.IPv4(10, 0, 0, 2).To4(), Port: 8080}
+)
+
+type testSegment struct {
+	src     *net.TCPAddr
+	dst     *net.TCPAddr
+	seq     uint32
+	flags   byte
+	payload []byte
+}
+
+func buildTCP(segment testSegment) []byte {
+	tcp := make([]byte, 20)
+	binary.BigEndian.PutUint16(tcp[0:2], uint16(segment.src.Port))
+	binary.BigEndian.PutUint16(tcp[2:4], uint16(segment.dst.Port))
+	binary.BigEndian.PutUint32(tcp[4:8], segment.seq)
+	tcp[12] = 5 << 4
+	tcp[13] = segment.flags
+	return append(tcp, segment.payload...)
+}
+
+// buildIPv4Frame build Ethernet frame with IPv4 TCP segment
+func buildIPv4Frame(segment testSegment) []byte {
+	tcp := buildTCP(segment)
+
+	ip := make([]byte, 20)
+	ip[0] = 0x45
+	binary.BigEndian.PutUint16(ip[2:4], uint16(len(ip)+len(tcp)))
+	ip[8] = 64
+	ip[9] = 6
+	copy(ip[12:16], segment.src.IP.To4())
+	copy(ip[16:20], segment.dst.IP.To4())
+
+	frame := make([]byte, 14)
+	binary.BigEndian.PutUint16(frame[12:14], 0x0800)
+	frame = append(frame, ip...)
+	return append(frame, tcp...)
+}
+
+// buildIPv6Packet build raw IPv6 packet with TCP segment
+func buildIPv6Packet(segment testSegment) []byte {
+	tcp := buildTCP(segment)
+
+	ip := make([]byte, 40)
+	ip[0] = 0x60
+	binary.BigEndian.PutUint16(ip[4:6], uint16(len(tcp)))
+	ip[6] = 6
+	ip[7] = 64
+	copy(ip[8:24], segment.src.IP.To16())
+	copy(ip[24:40], segment.dst.IP.To16())
+	return append(ip, tcp...)
+}
+
+// buildPcap build classic little-endian pcap file with microsecond timestamps
+func buildPcap(linkType uint32, frames ...[]byte) []byte {
+	buf := bytes.Buffer{}
+	header := make([]byte, 24)
+	binary.LittleEndian.PutUint32(header[0:4], 0xA1B2C3D4)
+	binary.LittleEndian.PutUint16(header[4:6], 2)
+	binary.LittleEndian.PutUint16(header[6:8], 4)
+	binary.LittleEndian.PutUint32(header[16:20], 65535)
+	binary.LittleEndian.PutUint32(header[20:24], linkType)
+	buf.Write(header)
+
+	for _, frame := range frames {
+		record := make([]byte, 16)
+		binary.LittleEndian.PutUint32(record[0:4], uint32(testTimestamp.Unix()))
+		binary.LittleEndian.PutUint32(record[4:8], uint32(testTimestamp.Nanosecond()/1000))
+		binary.LittleEndian.PutUint32(record[8:12], uint32(len(frame)))
+		binary.LittleEndian.PutUint32(record[12:16], uint32(len(frame)))
+		buf.Write(record)
+		buf.Write(frame)
+	}
+
+	return buf.Bytes()
+}
+
+func buildPcapngBlock(byteOrder binary.ByteOrder, blockType uint32, body []byte) []byte {
+	for len(body)%4 != 0 {
+		body = append(body, 0)
+	}
+	totalLen := uint32(12 + len(body))
+
+	block := make([]byte, 8)
+	byteOrder.PutUint32(block[0:4], blockType)
+	byteOrder.PutUint32(block[4:8], totalLen)
+	block = append(block, body...)
+	trailer := make([]byte, 4)
+	byteOrder.PutUint32(trailer, totalLen)
+	return append(block, trailer...)
+}
+
+// buildPcapng build pcapng file with one interface with nanosecond
+// timestamps and enhanced packet blocks
+func buildPcapng(byteOrder binary.ByteOrder, linkType uint16, frames ...[]byte) []byte {
+	buf := bytes.Buffer{}
+
+	shb := make([]byte, 16)
+	byteOrder.PutUint32(shb[0:4], 0x1A2B3C4D)
+	byteOrder.PutUint16(shb[4:6], 1)
+	binary.BigEndian.PutUint64(shb[8:16], 0xFFFFFFFFFFFFFFFF)
+	buf.Write(buildPcapngBlock(byteOrder, 0x0A0D0D0A, shb))
+
+	idb := make([]byte, 8)
+	byteOrder.PutUint16(idb[0:2], linkType)
+	tsResolOption := make([]byte, 4)
+	byteOrder.PutUint16(tsResolOption[0:2], 9)
+	byteOrder.PutUint16(tsResolOption[2:4], 1)
+	idb = append(idb, tsResolOption...)
+	idb = append(idb, 9, 0, 0, 0)
+	idb = append(idb, 0, 0, 0, 0)
+	buf.Write(buildPcapngBlock(byteOrder, 0x00000001, idb))
+
+	for _, frame := range frames {
+		timestamp := uint64(testTimestamp.UnixNano())
+		epb := make([]byte, 20)
+		byteOrder.PutUint32(epb[4:8], uint32(timestamp>>32))
+		byteOrder.PutUint32(epb[8:12], uint32(timestamp))
+		byteOrder.PutUint32(epb[12:16], uint32(len(frame)))
+		byteOrder.PutUint32(epb[16:20], uint32(len(frame)))
+		buf.Write(buildPcapngBlock(byteOrder, 0x00000006, append(epb, frame...)))
+	}
+
+	return buf.Bytes()
+}
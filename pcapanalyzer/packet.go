@@ -0,0 +1,226 @@
+package pcapanalyzer
+
+import (
+	"encoding/binary"
+	"net"
+)
+
+// Supported link types
+const (
+	LinkTypeNull      uint32 = 0
+	LinkTypeEthernet  uint32 = 1
+	LinkTypeRaw       uint32 = 101
+	LinkTypeLoop      uint32 = 108
+	LinkTypeLinuxSLL  uint32 = 113
+	LinkTypeIPv4      uint32 = 228
+	LinkTypeIPv6      uint32 = 229
+	LinkTypeLinuxSLL2 uint32 = 276
+)
+
+// EtherTypes
+const (
+	etherTypeIPv4 uint16 = 0x0800
+	etherTypeIPv6 uint16 = 0x86DD
+	etherTypeVLAN uint16 = 0x8100
+	etherTypeQinQ uint16 = 0x88A8
+)
+
+// IP protocol numbers and IPv6 extension headers
+const (
+	ipProtocolTCP   byte = 6
+	ipv6ExtHopByHop byte = 0
+	ipv6ExtRouting  byte = 43
+	ipv6ExtDestOpts byte = 60
+)
+
+// Header lengths
+const (
+	ethernetLen       = 14
+	vlanTagLen        = 4
+	linuxSLLLen       = 16
+	linuxSLL2Len      = 20
+	loopbackFamilyLen = 4
+	ipv4HeaderLen     = 20
+	ipv6HeaderLen     = 40
+	tcpHeaderLen      = 20
+)
+
+const ipv4FragmentMask = 0x3FFF
+
+// TCP flags
+const (
+	TCPFlagFIN byte = 0x01
+	TCPFlagSYN byte = 0x02
+	TCPFlagRST byte = 0x04
+	TCPFlagACK byte = 0x10
+)
+
+// Segment is TCP segment decoded from packet
+type Segment struct {
+	SrcAddr *net.TCPAddr
+	DstAddr *net.TCPAddr
+	Seq     uint32
+	Flags   byte
+	Payload []byte
+}
+
+// DecodeSegment decode TCP segment from link layer frame. False returned for
+// not TCP, fragmented or truncated packets.
+func DecodeSegment(packet Packet) (Segment, bool) {
+	etherType, ipBuf, ok := decodeLink(packet.LinkType, packet.Data)
+	if !ok {
+		return Segment{}, false
+	}
+
+	var srcIP, dstIP net.IP
+	var tcpBuf []byte
+	switch etherType {
+	case etherTypeIPv4:
+		srcIP, dstIP, tcpBuf, ok = decodeIPv4(ipBuf)
+	case etherTypeIPv6:
+		srcIP, dstIP, tcpBuf, ok = decodeIPv6(ipBuf)
+	default:
+		return Segment{}, false
+	}
+	if !ok {
+		return Segment{}, false
+	}
+
+	return decodeTCP(srcIP, dstIP, tcpBuf)
+}
+
+// decodeLink return EtherType of network layer and its data
+func decodeLink(linkType uint32, data []byte) (uint16, []byte, bool) {
+	switch linkType {
+	case LinkTypeEthernet:
+		if len(data) < ethernetLen {
+			return 0, nil, false
+		}
+		etherType, data := binary.BigEndian.Uint16(data[12:14]), data[ethernetLen:]
+		for etherType == etherTypeVLAN || etherType == etherTypeQinQ {
+			if len(data) < vlanTagLen {
+				return 0, nil, false
+			}
+			etherType, data = binary.BigEndian.Uint16(data[2:4]), data[vlanTagLen:]
+		}
+		return etherType, data, true
+	case LinkTypeLinuxSLL:
+		if len(data) < linuxSLLLen {
+			return 0, nil, false
+		}
+		return binary.BigEndian.Uint16(data[14:16]), data[linuxSLLLen:], true
+	case LinkTypeLinuxSLL2:
+		if len(data) < linuxSLL2Len {
+			return 0, nil, false
+		}
+		return binary.BigEndian.Uint16(data[0:2]), data[linuxSLL2Len:], true
+	case LinkTypeNull, LinkTypeLoop:
+		if len(data) < loopbackFamilyLen {
+			return 0, nil, false
+		}
+		return ipVersionEtherType(data[loopbackFamilyLen:]), data[loopbackFamilyLen:], true
+	case LinkTypeRaw:
+		return ipVersionEtherType(data), data, true
+	case LinkTypeIPv4:
+		return etherTypeIPv4, data, true
+	case LinkTypeIPv6:
+		return etherTypeIPv6, data, true
+	default:
+		return 0, nil, false
+	}
+}
+
+// ipVersionEtherType detect IP version by first nibble. Loopback family
+// values differ between systems, so they not used.
+func ipVersionEtherType(data []byte) uint16 {
+	if len(data) == 0 {
+		return 0
+	}
+
+	switch data[0] >> 4 {
+	case 4:
+		return etherTypeIPv4
+	case 6:
+		return etherTypeIPv6
+	default:
+		return 0
+	}
+}
+
+func decodeIPv4(data []byte) (net.IP, net.IP, []byte, bool) {
+	if len(data) < ipv4HeaderLen {
+		return nil, nil, nil, false
+	}
+
+	headerLen := int(data[0]&0x0F) * 4
+	totalLen := int(binary.BigEndian.Uint16(data[2:4]))
+	if headerLen < ipv4HeaderLen || (totalLen != 0 && totalLen < headerLen) || len(data) < headerLen {
+		return nil, nil, nil, false
+	}
+	// Trim Ethernet padding. Length can be zero with TCP segmentation offload.
+	if totalLen != 0 && totalLen < len(data) {
+		data = data[:totalLen]
+	}
+
+	if binary.BigEndian.Uint16(data[6:8])&ipv4FragmentMask != 0 || data[9] != ipProtocolTCP {
+		return nil, nil, nil, false
+	}
+
+	srcIP := net.IP(append([]byte(nil), data[12:16]...))
+	dstIP := net.IP(append([]byte(nil), data[16:20]...))
+	return srcIP, dstIP, data[headerLen:], true
+}
+
+func decodeIPv6(data []byte) (net.IP, net.IP, []byte, bool) {
+	if len(data) < ipv6HeaderLen {
+		return nil, nil, nil, false
+	}
+
+	payloadLen := int(binary.BigEndian.Uint16(data[4:6]))
+	nextHeader := data[6]
+	srcIP := net.IP(append([]byte(nil), data[8:24]...))
+	dstIP := net.IP(append([]byte(nil), data[24:40]...))
+
+	data = data[ipv6HeaderLen:]
+	if payloadLen != 0 && payloadLen < len(data) {
+		data = data[:payloadLen]
+	}
+
+	for {
+		switch nextHeader {
+		case ipProtocolTCP:
+			return srcIP, dstIP, data, true
+		case ipv6ExtHopByHop, ipv6ExtRouting, ipv6ExtDestOpts:
+			if len(data) < 8 {
+				return nil, nil, nil, false
+			}
+			extLen := (int(data[1]) + 1) * 8
+			if len(data) < extLen {
+				return nil, nil, nil, false
+			}
+			nextHeader, data = data[0], data[extLen:]
+		default:
+			// Fragments and other protocols not supported
+			return nil, nil, nil, false
+		}
+	}
+}
+
+func decodeTCP(srcIP, dstIP net.IP, data []byte) (Segment, bool) {
+	if len(data) < tcpHeaderLen {
+		return Segment{}, false
+	}
+
+	headerLen := int(data[12]>>4) * 4
+	if headerLen < tcpHeaderLen || len(data) < headerLen {
+		return Segment{}, false
+	}
+
+	return Segment{
+		SrcAddr: &net.TCPAddr{IP: srcIP, Port: int(binary.BigEndian.Uint16(data[0:2]))},
+		DstAddr: &net.TCPAddr{IP: dstIP, Port: int(binary.BigEndian.Uint16(data[2:4]))},
+		Seq:     binary.BigEndian.Uint32(data[4:8]),
+		Flags:   data[13],
+		Payload: data[headerLen:],
+	}, true
+}
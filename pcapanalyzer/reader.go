@@ -0,0 +1,345 @@
+// Package pcapanalyzer find proxyprotocol headers in pcap and pcapng capture
+// files without libpcap.
+//
+// First segments of each TCP stream reassembled and decoded with text and
+// binary header parsers. Result is report per TCP connection.
+package pcapanalyzer
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"io"
+	"time"
+)
+
+// Capture errors
+var (
+	ErrUnknownFormat    = errors.New("unknown capture file format")
+	ErrInvalidBlock     = errors.New("invalid pcapng block")
+	ErrUnknownInterface = errors.New("unknown interface")
+)
+
+// Capture file magic numbers
+const (
+	pcapMagicMicroseconds uint32 = 0xA1B2C3D4
+	pcapMagicNanoseconds  uint32 = 0xA1B23C4D
+	pcapngBlockTypeSHB    uint32 = 0x0A0D0D0A
+	pcapngByteOrderMagic  uint32 = 0x1A2B3C4D
+)
+
+// pcapng block types
+const (
+	pcapngBlockTypeIDB uint32 = 0x00000001
+	pcapngBlockTypePB  uint32 = 0x00000002
+	pcapngBlockTypeSPB uint32 = 0x00000003
+	pcapngBlockTypeEPB uint32 = 0x00000006
+)
+
+const (
+	pcapHeaderLen        = 24
+	pcapRecordHeaderLen  = 16
+	pcapngBlockHeaderLen = 8
+	pcapngOptionTSResol  = 9
+	// maxBlockLen limit memory used by broken files
+	maxBlockLen = 16 << 20
+)
+
+// Packet is captured link layer frame
+type Packet struct {
+	Timestamp time.Time
+	LinkType  uint32
+	Data      []byte
+}
+
+// PacketReader read packets from capture file
+type PacketReader interface {
+	ReadPacket() (Packet, error)
+}
+
+// NewPacketReader detect pcap or pcapng format by magic number and return
+// reader of packets
+func NewPacketReader(reader io.Reader) (PacketReader, error) {
+	bufReader := bufio.NewReader(reader)
+
+	magicBuf, err := bufReader.Peek(4)
+	if err != nil {
+		return nil, err
+	}
+
+	if binary.LittleEndian.Uint32(magicBuf) == pcapngBlockTypeSHB {
+		return &pcapngReader{reader: bufReader}, nil
+	}
+
+	return newPcapReader(bufReader)
+}
+
+// pcapReader read classic pcap format
+type pcapReader struct {
+	reader    io.Reader
+	byteOrder binary.ByteOrder
+	linkType  uint32
+	tsUnit    time.Duration
+}
+
+func newPcapReader(reader io.Reader) (*pcapReader, error) {
+	headerBuf := make([]byte, pcapHeaderLen)
+	if _, err := io.ReadFull(reader, headerBuf); err != nil {
+		return nil, err
+	}
+
+	pcap := &pcapReader{reader: reader}
+
+	var magic uint32
+	for _, byteOrder := range []binary.ByteOrder{binary.LittleEndian, binary.BigEndian} {
+		magic = byteOrder.Uint32(headerBuf)
+		if magic == pcapMagicMicroseconds || magic == pcapMagicNanoseconds {
+			pcap.byteOrder = byteOrder
+			break
+		}
+	}
+
+	switch magic {
+	case pcapMagicMicroseconds:
+		pcap.tsUnit = time.Microsecond
+	case pcapMagicNanoseconds:
+		pcap.tsUnit = time.Nanosecond
+	default:
+		return nil, ErrUnknownFormat
+	}
+
+	pcap.linkType = pcap.byteOrder.Uint32(headerBuf[20:24]) & 0x0FFFFFFF
+
+	return pcap, nil
+}
+
+func (pcap *pcapReader) ReadPacket() (Packet, error) {
+	recordBuf := make([]byte, pcapRecordHeaderLen)
+	if _, err := io.ReadFull(pcap.reader, recordBuf); err != nil {
+		return Packet{}, err
+	}
+
+	seconds := pcap.byteOrder.Uint32(recordBuf[0:4])
+	fraction := pcap.byteOrder.Uint32(recordBuf[4:8])
+	capturedLen := pcap.byteOrder.Uint32(recordBuf[8:12])
+	if capturedLen > maxBlockLen {
+		return Packet{}, ErrUnknownFormat
+	}
+
+	data := make([]byte, capturedLen)
+	if _, err := io.ReadFull(pcap.reader, data); err != nil {
+		return Packet{}, unexpectedEOF(err)
+	}
+
+	return Packet{
+		Timestamp: time.Unix(int64(seconds), int64(fraction)*int64(pcap.tsUnit)).UTC(),
+		LinkType:  pcap.linkType,
+		Data:      data,
+	}, nil
+}
+
+// pcapngInterface is interface described by IDB
+type pcapngInterface struct {
+	linkType uint32
+	// tsResolution is count of timestamp units per second
+	tsResolution uint64
+}
+
+// pcapngReader read pcapng format. Each section may use own byte order and
+// interfaces.
+type pcapngReader struct {
+	reader     io.Reader
+	byteOrder  binary.ByteOrder
+	interfaces []pcapngInterface
+}
+
+func (pcapng *pcapngReader) ReadPacket() (Packet, error) {
+	for {
+		blockType, body, err := pcapng.readBlock()
+		if err != nil {
+			return Packet{}, err
+		}
+
+		switch blockType {
+		case pcapngBlockTypeIDB:
+			if err := pcapng.parseInterface(body); err != nil {
+				return Packet{}, err
+			}
+		case pcapngBlockTypeEPB:
+			return pcapng.parseEnhancedPacket(body)
+		case pcapngBlockTypeSPB:
+			return pcapng.parseSimplePacket(body)
+		}
+	}
+}
+
+// readBlock return block type and body without trailing total length
+func (pcapng *pcapngReader) readBlock() (uint32, []byte, error) {
+	headerBuf := make([]byte, pcapngBlockHeaderLen)
+	if _, err := io.ReadFull(pcapng.reader, headerBuf); err != nil {
+		return 0, nil, err
+	}
+
+	blockType := binary.LittleEndian.Uint32(headerBuf)
+	if blockType == pcapngBlockTypeSHB {
+		return pcapng.readSectionHeader(headerBuf)
+	}
+	if pcapng.byteOrder == nil {
+		return 0, nil, ErrInvalidBlock
+	}
+	blockType = pcapng.byteOrder.Uint32(headerBuf)
+
+	body, err := pcapng.readBlockBody(pcapng.byteOrder.Uint32(headerBuf[4:]))
+	return blockType, body, err
+}
+
+// readSectionHeader detect section byte order and reset interfaces
+func (pcapng *pcapngReader) readSectionHeader(headerBuf []byte) (uint32, []byte, error) {
+	magicBuf := make([]byte, 4)
+	if _, err := io.ReadFull(pcapng.reader, magicBuf); err != nil {
+		return 0, nil, unexpectedEOF(err)
+	}
+
+	switch pcapngByteOrderMagic {
+	case binary.LittleEndian.Uint32(magicBuf):
+		pcapng.byteOrder = binary.LittleEndian
+	case binary.BigEndian.Uint32(magicBuf):
+		pcapng.byteOrder = binary.BigEndian
+	default:
+		return 0, nil, ErrUnknownFormat
+	}
+	pcapng.interfaces = nil
+
+	totalLen := pcapng.byteOrder.Uint32(headerBuf[4:])
+	if totalLen < uint32(pcapngBlockHeaderLen+len(magicBuf)) {
+		return 0, nil, ErrInvalidBlock
+	}
+
+	body, err := pcapng.readBlockBody(totalLen - uint32(len(magicBuf)))
+	return pcapngBlockTypeSHB, body, err
+}
+
+func (pcapng *pcapngReader) readBlockBody(totalLen uint32) ([]byte, error) {
+	// block header and trailing total length
+	const blockMetaLen = pcapngBlockHeaderLen + 4
+
+	if totalLen < blockMetaLen || totalLen > maxBlockLen {
+		return nil, ErrInvalidBlock
+	}
+
+	bodyBuf := make([]byte, totalLen-pcapngBlockHeaderLen)
+	if _, err := io.ReadFull(pcapng.reader, bodyBuf); err != nil {
+		return nil, unexpectedEOF(err)
+	}
+
+	return bodyBuf[:len(bodyBuf)-4], nil
+}
+
+func (pcapng *pcapngReader) parseInterface(body []byte) error {
+	if len(body) < 8 {
+		return ErrInvalidBlock
+	}
+
+	iface := pcapngInterface{
+		linkType:     uint32(pcapng.byteOrder.Uint16(body[0:2])),
+		tsResolution: 1000000,
+	}
+
+	options := body[8:]
+	for len(options) >= 4 {
+		code := pcapng.byteOrder.Uint16(options[0:2])
+		length := int(pcapng.byteOrder.Uint16(options[2:4]))
+		options = options[4:]
+		if length > len(options) {
+			return ErrInvalidBlock
+		}
+
+		if code == pcapngOptionTSResol && length >= 1 {
+			iface.tsResolution = tsResolution(options[0])
+		}
+
+		paddedLen := (length + 3) &^ 3
+		if paddedLen > len(options) {
+			paddedLen = len(options)
+		}
+		options = options[paddedLen:]
+	}
+
+	pcapng.interfaces = append(pcapng.interfaces, iface)
+	return nil
+}
+
+// tsResolution decode if_tsresol option: power of 10 or power of 2 when
+// most significant bit set
+func tsResolution(value byte) uint64 {
+	base, exponent := uint64(10), value
+	if value&0x80 != 0 {
+		base, exponent = 2, value&0x7F
+	}
+
+	resolution := uint64(1)
+	for i := byte(0); i < exponent && resolution < 1<<60/base; i++ {
+		resolution *= base
+	}
+	return resolution
+}
+
+func (pcapng *pcapngReader) parseEnhancedPacket(body []byte) (Packet, error) {
+	const epbHeaderLen = 20
+	if len(body) < epbHeaderLen {
+		return Packet{}, ErrInvalidBlock
+	}
+
+	interfaceID := pcapng.byteOrder.Uint32(body[0:4])
+	if int(interfaceID) >= len(pcapng.interfaces) {
+		return Packet{}, ErrUnknownInterface
+	}
+	iface := pcapng.interfaces[interfaceID]
+
+	timestamp := uint64(pcapng.byteOrder.Uint32(body[4:8]))<<32 | uint64(pcapng.byteOrder.Uint32(body[8:12]))
+	capturedLen := pcapng.byteOrder.Uint32(body[12:16])
+	if uint64(capturedLen) > uint64(len(body)-epbHeaderLen) {
+		return Packet{}, ErrInvalidBlock
+	}
+
+	seconds := timestamp / iface.tsResolution
+	fraction := timestamp % iface.tsResolution
+	nanoseconds := fraction * uint64(time.Second) / iface.tsResolution
+
+	return Packet{
+		Timestamp: time.Unix(int64(seconds), int64(nanoseconds)).UTC(),
+		LinkType:  iface.linkType,
+		Data:      body[epbHeaderLen : epbHeaderLen+capturedLen],
+	}, nil
+}
+
+// parseSimplePacket parse SPB. It always belong to first interface and have
+// not timestamp.
+func (pcapng *pcapngReader) parseSimplePacket(body []byte) (Packet, error) {
+	const spbHeaderLen = 4
+	if len(body) < spbHeaderLen {
+		return Packet{}, ErrInvalidBlock
+	}
+	if len(pcapng.interfaces) == 0 {
+		return Packet{}, ErrUnknownInterface
+	}
+
+	originalLen := pcapng.byteOrder.Uint32(body[0:4])
+	data := body[spbHeaderLen:]
+	if uint64(originalLen) < uint64(len(data)) {
+		data = data[:originalLen]
+	}
+
+	return Packet{
+		LinkType: pcapng.interfaces[0].linkType,
+		Data:     data,
+	}, nil
+}
+
+// unexpectedEOF convert EOF inside record into io.ErrUnexpectedEOF
+func unexpectedEOF(err error) error {
+	if err == io.EOF {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
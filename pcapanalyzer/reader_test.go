@@ -0,0 +1,64 @@
+package pcapanalyzer_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"testing"
+
+	"github.com/c0va23/go-proxyprotocol/pcapanalyzer"
+)
+
+func TestNewPacketReader(t *testing.T) {
+	frame := buildIPv4Frame(testSegment{src: testLBAddr, dst: testSrvAddr, flags: pcapanalyzer.TCPFlagSYN})
+
+	captures := map[string][]byte{
+		"pcap":                 buildPcap(pcapanalyzer.LinkTypeEthernet, frame),
+		"pcapng little endian": buildPcapng(binary.LittleEndian, uint16(pcapanalyzer.LinkTypeEthernet), frame),
+		"pcapng big endian":    buildPcapng(binary.BigEndian, uint16(pcapanalyzer.LinkTypeEthernet), frame),
+	}
+
+	for name, capture := range captures {
+		t.Run(name, func(t *testing.T) {
+			reader, err := pcapanalyzer.NewPacketReader(bytes.NewReader(capture))
+			if err != nil {
+				t.Fatalf("Unexpected error %s", err)
+			}
+
+			packet, err := reader.ReadPacket()
+			if err != nil {
+				t.Fatalf("Unexpected error %s", err)
+			}
+
+			if packet.LinkType != pcapanalyzer.LinkTypeEthernet || !bytes.Equal(packet.Data, frame) {
+				t.Errorf("Unexpected packet %+v", packet)
+			}
+			if !packet.Timestamp.Equal(testTimestamp) {
+				t.Errorf("Unexpected timestamp %s", packet.Timestamp)
+			}
+
+			if _, err := reader.ReadPacket(); err != io.EOF {
+				t.Errorf("Unexpected error %v", err)
+			}
+		})
+	}
+
+	t.Run("truncated pcap", func(t *testing.T) {
+		capture := buildPcap(pcapanalyzer.LinkTypeEthernet, frame)
+		reader, err := pcapanalyzer.NewPacketReader(bytes.NewReader(capture[:len(capture)-1]))
+		if err != nil {
+			t.Fatalf("Unexpected error %s", err)
+		}
+
+		if _, err := reader.ReadPacket(); err != io.ErrUnexpectedEOF {
+			t.Errorf("Unexpected error %v", err)
+		}
+	})
+
+	t.Run("unknown format", func(t *testing.T) {
+		_, err := pcapanalyzer.NewPacketReader(bytes.NewReader(make([]byte, 24)))
+		if err != pcapanalyzer.ErrUnknownFormat {
+			t.Errorf("Unexpected error %v", err)
+		}
+	})
+}
@@ -13,10 +13,56 @@ import (
 	"net"
 )
 
+// Header versions
+const (
+	Version1 byte = 1
+	Version2 byte = 2
+)
+
+// Command of proxyprotocol header
+type Command byte
+
+// Header commands. Text headers always use CommandProxy.
+const (
+	// CommandProxy mean that connection relayed on behalf of another node
+	CommandProxy Command = iota
+	// CommandLocal mean that connection established by proxy itself (health
+	// check) and real connection endpoints must be used
+	CommandLocal
+)
+
+// String return command name
+func (command Command) String() string {
+	switch command {
+	case CommandProxy:
+		return "PROXY"
+	case CommandLocal:
+		return "LOCAL"
+	default:
+		return "UNKNOWN"
+	}
+}
+
 // Header struct represent header parsing result
 type Header struct {
 	SrcAddr net.Addr
 	DstAddr net.Addr
+	// Version is Version1 for text header and Version2 for binary header
+	Version byte
+	Command Command
+	// AddressFamily is one of BinaryAF* constants
+	AddressFamily byte
+	// Transport is one of BinaryTP* constants. Text TCP4 and TCP6 headers use
+	// BinaryTPStream, UNKNOWN headers use BinaryTPUnspec.
+	Transport byte
+	// TLVs contain Type-Length-Value vectors of binary header in received order
+	TLVs []TLV
+	// TLS contain decoded PP2_TYPE_SSL TLV. Nil when TLV not received.
+	TLS *TLSInfo
+	// Decoded contain values of TLVs decoded by TLVDecoderRegistry
+	Decoded map[byte]interface{}
+	// Raw contain exact header bytes consumed from connection
+	Raw []byte
 }
 
 // HeaderParserBuilder build HeaderParser's
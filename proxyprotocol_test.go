@@ -0,0 +1,21 @@
+package proxyprotocol_test
+
+import (
+	"testing"
+
+	"github.com/c0va23/go-proxyprotocol"
+)
+
+func TestCommand_String(t *testing.T) {
+	commandNames := map[proxyprotocol.Command]string{
+		proxyprotocol.CommandProxy:  "PROXY",
+		proxyprotocol.CommandLocal:  "LOCAL",
+		proxyprotocol.Command(0xFF): "UNKNOWN",
+	}
+
+	for command, expectedName := range commandNames {
+		if name := command.String(); name != expectedName {
+			t.Errorf("Unexpected name %s for command %d", name, command)
+		}
+	}
+}
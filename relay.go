@@ -0,0 +1,91 @@
+package proxyprotocol
+
+import (
+	"io"
+	"net"
+
+	"github.com/c0va23/go-proxyprotocol/internal/acceptretry"
+)
+
+// Relay accept connections from listener and relay each of them to backend
+// address like HAProxy with send-proxy option. Dialer write header with
+// addresses of accepted connection into backend connection.
+//
+// Temporary Accept errors (like EMFILE) logged and Accept retried with
+// backoff, like http.Server.Serve do. Relay return permanent Accept error.
+// Errors of relayed connections logged.
+func Relay(listener net.Listener, dialer Dialer, network, address string, logger Logger) error {
+	logger = FallbackLogger{Logger: logger}
+
+	for {
+		conn, err := acceptretry.Accept(listener, logger)
+		if err != nil {
+			return err
+		}
+
+		go func() {
+			if err := RelayConn(conn, dialer, network, address); err != nil {
+				logger.Printf("Relay connection from %s error: %s", conn.RemoteAddr(), err)
+			}
+		}()
+	}
+}
+
+// RelayConn dial backend address with header describing conn and copy data
+// between connections. Both connections closed on return.
+func RelayConn(conn net.Conn, dialer Dialer, network, address string) error {
+	defer conn.Close()
+
+	backendConn, err := dialer.WithConn(conn).Dial(network, address)
+	if err != nil {
+		return err
+	}
+	defer backendConn.Close()
+
+	return Pipe(conn, backendConn)
+}
+
+type closeWriter interface {
+	CloseWrite() error
+}
+
+// Pipe copy data between connections in both directions until both
+// directions done.
+//
+// When one connection reach EOF, then write side of other connection closed
+// with CloseWrite, so half-closed connections relayed correctly. Connections
+// without CloseWrite closed entirely. On copy error both connections closed.
+func Pipe(left, right net.Conn) error {
+	errs := make(chan error, 2)
+	go func() {
+		errs <- copyHalf(right, left)
+	}()
+	go func() {
+		errs <- copyHalf(left, right)
+	}()
+
+	err := <-errs
+	if err != nil {
+		left.Close()
+		right.Close()
+	}
+
+	if secondErr := <-errs; err == nil {
+		err = secondErr
+	}
+
+	return err
+}
+
+// copyHalf copy data from src to dst and close write side of dst
+func copyHalf(dst, src net.Conn) error {
+	_, err := io.Copy(dst, src)
+
+	if closer, ok := dst.(closeWriter); ok {
+		closer.CloseWrite() // nolint: errcheck
+	} else {
+		dst.Close()
+	}
+
+	return err
+}
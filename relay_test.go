@@ -0,0 +1,151 @@
+package proxyprotocol_test
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"testing"
+
+	"github.com/c0va23/go-proxyprotocol"
+	"github.com/golang/mock/gomock"
+)
+
+// startHalfCloseBackend accept one connection, read request until EOF and
+// reply with remote address and request
+func startHalfCloseBackend(t *testing.T) net.Listener {
+	rawListener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	listener := proxyprotocol.NewDefaultListener(rawListener)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		request, err := ioutil.ReadAll(conn)
+		if err != nil {
+			t.Errorf("Read error: %s", err)
+			return
+		}
+		fmt.Fprintf(conn, "%s %s", conn.RemoteAddr(), request)
+	}()
+
+	return rawListener
+}
+
+func TestRelay(t *testing.T) {
+	for _, version := range []byte{proxyprotocol.Version1, proxyprotocol.Version2} {
+		t.Run(fmt.Sprintf("version %d", version), func(t *testing.T) {
+			backendListener := startHalfCloseBackend(t)
+			defer backendListener.Close()
+
+			relayListener, err := net.Listen("tcp", "127.0.0.1:0")
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer relayListener.Close()
+
+			go proxyprotocol.Relay( // nolint: errcheck
+				relayListener,
+				proxyprotocol.NewDialer(version),
+				"tcp",
+				backendListener.Addr().String(),
+				proxyprotocol.LoggerFunc(t.Logf),
+			)
+
+			conn, err := net.Dial("tcp", relayListener.Addr().String())
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer conn.Close()
+
+			if _, err := conn.Write([]byte("request")); err != nil {
+				t.Fatal(err)
+			}
+			if err := conn.(*net.TCPConn).CloseWrite(); err != nil {
+				t.Fatal(err)
+			}
+
+			response, err := ioutil.ReadAll(conn)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			expectedResponse := conn.LocalAddr().String() + " request"
+			if string(response) != expectedResponse {
+				t.Errorf("Unexpected response %q", response)
+			}
+		})
+	}
+}
+
+type temporaryError struct{}
+
+func (temporaryError) Error() string   { return "too many open files" }
+func (temporaryError) Timeout() bool   { return false }
+func (temporaryError) Temporary() bool { return true }
+
+func TestRelay_acceptError(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	permanentErr := errors.New("listener closed")
+	listener := NewMockListener(mockCtrl)
+	gomock.InOrder(
+		listener.EXPECT().Accept().Return(nil, temporaryError{}).Times(2),
+		listener.EXPECT().Accept().Return(nil, permanentErr),
+	)
+
+	err := proxyprotocol.Relay(
+		listener,
+		proxyprotocol.NewDialer(proxyprotocol.Version1),
+		"tcp",
+		"127.0.0.1:1",
+		proxyprotocol.LoggerFunc(t.Logf),
+	)
+	if err != permanentErr {
+		t.Errorf("Unexpected error %v", err)
+	}
+}
+
+func TestRelayConn(t *testing.T) {
+	t.Run("when backend unavailable", func(t *testing.T) {
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer listener.Close()
+
+		backendListener, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			t.Fatal(err)
+		}
+		backendAddr := backendListener.Addr().String()
+		backendListener.Close()
+
+		clientConn, err := net.Dial("tcp", listener.Addr().String())
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer clientConn.Close()
+
+		conn, err := listener.Accept()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		err = proxyprotocol.RelayConn(conn, proxyprotocol.NewDialer(proxyprotocol.Version1), "tcp", backendAddr)
+		if _, ok := err.(*net.OpError); !ok {
+			t.Errorf("Unexpected error %v", err)
+		}
+
+		if _, err := clientConn.Read(make([]byte, 1)); err == nil {
+			t.Error("Expected closed connection")
+		}
+	})
+}
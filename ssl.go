@@ -0,0 +1,87 @@
+package proxyprotocol
+
+import (
+	"encoding/binary"
+	"errors"
+)
+
+// ErrInvalidSSLTLV returned when PP2_TYPE_SSL TLV or its sub-TLVs malformed
+var ErrInvalidSSLTLV = errors.New("invalid SSL TLV")
+
+// PP2_TYPE_SSL value byte position
+const (
+	sslClientPos      = 0
+	sslVerifyStartPos = 1
+	sslVerifyEndPos   = 5
+)
+
+// TLSInfo represent PP2_TYPE_SSL TLV sent by TLS-terminating proxy
+type TLSInfo struct {
+	// Client contain bit field of TLVClient* flags
+	Client byte
+	// Verify is zero when client presented certificate and it was verified
+	Verify uint32
+	// Version, CN, Cipher, SigAlg and KeyAlg contain values of sub-TLVs
+	Version string
+	CN      string
+	Cipher  string
+	SigAlg  string
+	KeyAlg  string
+	// TLVs contain all sub-TLVs in received order
+	TLVs []TLV
+}
+
+// ClientSSL return true when client connected over SSL/TLS
+func (tlsInfo *TLSInfo) ClientSSL() bool {
+	return tlsInfo.Client&TLVClientSSL != 0
+}
+
+// ClientCertConn return true when client provided certificate over current connection
+func (tlsInfo *TLSInfo) ClientCertConn() bool {
+	return tlsInfo.Client&TLVClientCertConn != 0
+}
+
+// ClientCertSess return true when client provided certificate at least once over
+// the TLS session this connection belongs to
+func (tlsInfo *TLSInfo) ClientCertSess() bool {
+	return tlsInfo.Client&TLVClientCertSess != 0
+}
+
+// Verified return true when client certificate successfully verified
+func (tlsInfo *TLSInfo) Verified() bool {
+	return tlsInfo.Verify == 0
+}
+
+func parseSSLTLV(value []byte) (*TLSInfo, error) {
+	if len(value) < sslVerifyEndPos {
+		return nil, ErrInvalidSSLTLV
+	}
+
+	subTLVs, err := parseTLVs(value[sslVerifyEndPos:])
+	if err != nil {
+		return nil, ErrInvalidSSLTLV
+	}
+
+	tlsInfo := &TLSInfo{
+		Client: value[sslClientPos],
+		Verify: binary.BigEndian.Uint32(value[sslVerifyStartPos:sslVerifyEndPos]),
+		TLVs:   subTLVs,
+	}
+
+	for _, subTLV := range subTLVs {
+		switch subTLV.Type {
+		case TLVSubtypeSSLVersion:
+			tlsInfo.Version = string(subTLV.Value)
+		case TLVSubtypeSSLCN:
+			tlsInfo.CN = string(subTLV.Value)
+		case TLVSubtypeSSLCipher:
+			tlsInfo.Cipher = string(subTLV.Value)
+		case TLVSubtypeSSLSigAlg:
+			tlsInfo.SigAlg = string(subTLV.Value)
+		case TLVSubtypeSSLKeyAlg:
+			tlsInfo.KeyAlg = string(subTLV.Value)
+		}
+	}
+
+	return tlsInfo, nil
+}
@@ -0,0 +1,104 @@
+package proxyprotocol_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/c0va23/go-proxyprotocol"
+)
+
+func TestParseSSLTLV(t *testing.T) {
+	logger := proxyprotocol.LoggerFunc(t.Logf)
+	binaryHeaderParser := proxyprotocol.NewBinaryHeaderParser(logger)
+	commandVersion := proxyprotocol.BinaryCommandProxy | proxyprotocol.BinaryVersion2
+
+	buildData := func(sslValue []byte) []byte {
+		return buildBinaryHeader(
+			commandVersion,
+			proxyprotocol.BinaryProtocolTCPoverIPv4,
+			testIPv4AddressData,
+			buildTLV(proxyprotocol.TLVTypeSSL, sslValue),
+		)
+	}
+
+	t.Run("valid SSL TLV", func(t *testing.T) {
+		subTLVs := append(buildTLV(proxyprotocol.TLVSubtypeSSLVersion, []byte("TLSv1.3")),
+			buildTLV(proxyprotocol.TLVSubtypeSSLCN, []byte("client.example.com"))...)
+		subTLVs = append(subTLVs, buildTLV(proxyprotocol.TLVSubtypeSSLCipher, []byte("TLS_AES_128_GCM_SHA256"))...)
+		subTLVs = append(subTLVs, buildTLV(proxyprotocol.TLVSubtypeSSLSigAlg, []byte("SHA256"))...)
+		subTLVs = append(subTLVs, buildTLV(proxyprotocol.TLVSubtypeSSLKeyAlg, []byte("RSA2048"))...)
+
+		client := proxyprotocol.TLVClientSSL | proxyprotocol.TLVClientCertConn
+		sslValue := append([]byte{client, 0, 0, 0, 0}, subTLVs...)
+
+		header, err := binaryHeaderParser.Parse(newTestReader(buildData(sslValue)))
+		if err != nil {
+			t.Fatalf("Unexpected error %s", err)
+		}
+
+		tlsInfo := header.TLS
+		if tlsInfo == nil {
+			t.Fatal("TLS info not decoded")
+		}
+
+		if len(tlsInfo.TLVs) != 5 {
+			t.Errorf("Unexpected sub-TLVs %v", tlsInfo.TLVs)
+		}
+
+		if !tlsInfo.ClientSSL() || !tlsInfo.ClientCertConn() || tlsInfo.ClientCertSess() {
+			t.Errorf("Unexpected client flags %02x", tlsInfo.Client)
+		}
+
+		if !tlsInfo.Verified() {
+			t.Errorf("Unexpected verify result %d", tlsInfo.Verify)
+		}
+
+		expectedTLSInfo := proxyprotocol.TLSInfo{
+			Client:  client,
+			Version: "TLSv1.3",
+			CN:      "client.example.com",
+			Cipher:  "TLS_AES_128_GCM_SHA256",
+			SigAlg:  "SHA256",
+			KeyAlg:  "RSA2048",
+		}
+		expectedTLSInfo.TLVs = tlsInfo.TLVs
+		if !reflect.DeepEqual(*tlsInfo, expectedTLSInfo) {
+			t.Errorf("Unexpected TLS info %+v", tlsInfo)
+		}
+	})
+
+	t.Run("not verified certificate", func(t *testing.T) {
+		sslValue := []byte{proxyprotocol.TLVClientSSL, 0, 0, 0, 1}
+
+		header, err := binaryHeaderParser.Parse(newTestReader(buildData(sslValue)))
+		if err != nil {
+			t.Fatalf("Unexpected error %s", err)
+		}
+
+		if header.TLS.Verified() {
+			t.Errorf("Unexpected verify result %d", header.TLS.Verify)
+		}
+	})
+
+	t.Run("too short value", func(t *testing.T) {
+		testParser(t, testParserArgs{
+			headerParser: binaryHeaderParser,
+			data:         buildData([]byte{proxyprotocol.TLVClientSSL, 0, 0}),
+			err:          proxyprotocol.ErrInvalidSSLTLV,
+			readAll:      true,
+		})
+	})
+
+	t.Run("malformed sub-TLV length", func(t *testing.T) {
+		sslValue := []byte{
+			proxyprotocol.TLVClientSSL, 0, 0, 0, 0,
+			proxyprotocol.TLVSubtypeSSLVersion, 0, 10, 'T', 'L', 'S',
+		}
+		testParser(t, testParserArgs{
+			headerParser: binaryHeaderParser,
+			data:         buildData(sslValue),
+			err:          proxyprotocol.ErrInvalidSSLTLV,
+			readAll:      true,
+		})
+	})
+}
@@ -9,6 +9,9 @@ var (
 	TextCRLF      = []byte{TextCR, TextLF}
 )
 
+// TextHeaderMaxLen is maximal length of proxyprotocol v1 header with CRLF
+var TextHeaderMaxLen = 107
+
 var (
 	textSignatureLen    = len(TextSignature)
 	textAddressPartsLen = 4
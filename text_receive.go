@@ -46,23 +46,38 @@ func (parser TextHeaderParser) Parse(buf *bufio.Reader) (*Header, error) {
 		return nil, err
 	}
 
+	rawHeader := []byte(headerLine)
+
 	// Strip CR char on line end
 	if headerLine[len(headerLine)-2] == TextCR {
 		headerLine = headerLine[:len(headerLine)-2]
 	}
 
 	headerParts := strings.Split(headerLine, TextSeparator)
+	if len(headerParts) < 2 {
+		return nil, ErrUnknownProtocol
+	}
 
 	protocol := headerParts[1]
 
+	var header *Header
 	switch protocol {
 	case TextProtocolUnknown:
-		return nil, nil
+		header = &Header{AddressFamily: BinaryAFUnspec}
 	case TextProtocolIPv4, TextProtocolIPv6:
-		return parseTextHeader(headerParts)
+		header, err = parseTextHeader(headerParts)
+		if err != nil {
+			return nil, err
+		}
 	default:
 		return nil, ErrUnknownProtocol
 	}
+
+	header.Version = Version1
+	header.Command = CommandProxy
+	header.Raw = rawHeader
+
+	return header, nil
 }
 
 func parseTextHeader(headerParts []string) (*Header, error) {
@@ -89,6 +104,11 @@ func parseTextHeader(headerParts []string) (*Header, error) {
 		return nil, ErrInvalidPort
 	}
 
+	// TCP4 addresses must be IPv4
+	if headerParts[1] == TextProtocolIPv4 && (srcIP.To4() == nil || dstIP.To4() == nil) {
+		return nil, ErrInvalidIP
+	}
+
 	dstPortSrt := addressParts[3]
 	dstPort, err := strconv.ParseUint(dstPortSrt, 10, textPortBitSize)
 	if err != nil {
@@ -104,5 +124,14 @@ func parseTextHeader(headerParts []string) (*Header, error) {
 			IP:   dstIP,
 			Port: int(dstPort),
 		},
+		AddressFamily: textAddressFamily(headerParts[1]),
+		Transport:     BinaryTPStream,
 	}, nil
 }
+
+func textAddressFamily(protocol string) byte {
+	if protocol == TextProtocolIPv6 {
+		return BinaryAFInet6
+	}
+	return BinaryAFInet
+}
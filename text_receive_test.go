@@ -61,6 +61,15 @@ func TestParseTextHeader(t *testing.T) {
 		})
 	})
 
+	t.Run("without protocol", func(t *testing.T) {
+		data := append(proxyprotocol.TextSignature, proxyprotocol.TextCRLF...)
+		testParser(t, testParserArgs{
+			headerParser: textHeaderParser,
+			data:         data,
+			err:          proxyprotocol.ErrUnknownProtocol,
+		})
+	})
+
 	t.Run("unknown protocol", func(t *testing.T) {
 		data := append(proxyprotocol.TextSignature, []byte(proxyprotocol.TextSeparator)...)
 		data = append(data, []byte(proxyprotocol.TextProtocolUnknown)...)
@@ -68,8 +77,12 @@ func TestParseTextHeader(t *testing.T) {
 		testParser(t, testParserArgs{
 			headerParser: textHeaderParser,
 			data:         data,
-			header:       nil,
-			err:          nil,
+			header: &proxyprotocol.Header{
+				Version: proxyprotocol.Version1,
+				Command: proxyprotocol.CommandProxy,
+				Raw:     data,
+			},
+			err: nil,
 		})
 	})
 
@@ -95,6 +108,24 @@ func TestParseTextHeader(t *testing.T) {
 			})
 		})
 
+		t.Run("IPv6 src IP", func(t *testing.T) {
+			invalidData := buildTextHeader(data, "::1", "192.168.1.3", "1080", "12345")
+			testParser(t, testParserArgs{
+				headerParser: textHeaderParser,
+				data:         invalidData,
+				err:          proxyprotocol.ErrInvalidIP,
+			})
+		})
+
+		t.Run("IPv6 dst IP", func(t *testing.T) {
+			invalidData := buildTextHeader(data, "192.168.1.1", "::2", "1080", "12345")
+			testParser(t, testParserArgs{
+				headerParser: textHeaderParser,
+				data:         invalidData,
+				err:          proxyprotocol.ErrInvalidIP,
+			})
+		})
+
 		t.Run("invalid src port", func(t *testing.T) {
 			invalidData := buildTextHeader(data, "192.168.1.1", "192.168.1.3", "808080", "12345")
 			testParser(t, testParserArgs{
@@ -138,9 +169,14 @@ func TestParseTextHeader(t *testing.T) {
 					IP:   dstAddr,
 					Port: dstPort,
 				},
+				Version:       proxyprotocol.Version1,
+				Command:       proxyprotocol.CommandProxy,
+				AddressFamily: proxyprotocol.BinaryAFInet,
+				Transport:     proxyprotocol.BinaryTPStream,
 			}
 
 			validData := buildTextHeader(data, srcAddr.String(), dstAddr.String(), strconv.Itoa(srcPort), strconv.Itoa(dstPort))
+			expectedHeader.Raw = validData
 			testParser(t, testParserArgs{
 				headerParser: textHeaderParser,
 				data:         validData,
@@ -214,9 +250,14 @@ func TestParseTextHeader(t *testing.T) {
 					IP:   dstAddr,
 					Port: dstPort,
 				},
+				Version:       proxyprotocol.Version1,
+				Command:       proxyprotocol.CommandProxy,
+				AddressFamily: proxyprotocol.BinaryAFInet6,
+				Transport:     proxyprotocol.BinaryTPStream,
 			}
 
 			validData := buildTextHeader(data, srcAddr.String(), dstAddr.String(), strconv.Itoa(srcPort), strconv.Itoa(dstPort))
+			expectedHeader.Raw = validData
 			testParser(t, testParserArgs{
 				headerParser: textHeaderParser,
 				data:         validData,
@@ -0,0 +1,128 @@
+package proxyprotocol
+
+import (
+	"errors"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+)
+
+// Header encode errors
+var (
+	ErrUnsupportedAddress   = errors.New("unsupported address")
+	ErrInvalidAddressFamily = errors.New("invalid address family")
+	ErrHeaderTooLong        = errors.New("header too long")
+)
+
+var textIPv4MappedIPv6Prefix = "::ffff:"
+
+// FormatV1 encode header into proxyprotocol v1 (text) format.
+//
+// Header with LOCAL command or without addresses encoded as UNKNOWN.
+// Otherwise addresses must be *net.TCPAddr of the same address family.
+func (header *Header) FormatV1() ([]byte, error) {
+	parts := []string{string(TextSignature)}
+
+	if header.Command == CommandLocal || (header.SrcAddr == nil && header.DstAddr == nil) {
+		parts = append(parts, TextProtocolUnknown)
+	} else {
+		addressParts, err := header.textAddressParts()
+		if err != nil {
+			return nil, err
+		}
+		parts = append(parts, addressParts...)
+	}
+
+	headerLine := strings.Join(parts, TextSeparator) + string(TextCRLF)
+	if len(headerLine) > TextHeaderMaxLen {
+		return nil, ErrHeaderTooLong
+	}
+
+	return []byte(headerLine), nil
+}
+
+// WriteV1 write proxyprotocol v1 header into writer
+func (header *Header) WriteV1(writer io.Writer) error {
+	headerBuf, err := header.FormatV1()
+	if err != nil {
+		return err
+	}
+
+	_, err = writer.Write(headerBuf)
+	return err
+}
+
+func (header *Header) textAddressParts() ([]string, error) {
+	srcAddr, srcOk := header.SrcAddr.(*net.TCPAddr)
+	dstAddr, dstOk := header.DstAddr.(*net.TCPAddr)
+	if !srcOk || !dstOk {
+		return nil, ErrUnsupportedAddress
+	}
+
+	addressFamily, err := header.ipAddressFamily(srcAddr.IP, dstAddr.IP)
+	if err != nil {
+		return nil, err
+	}
+
+	if !validPort(srcAddr.Port) || !validPort(dstAddr.Port) {
+		return nil, ErrInvalidPort
+	}
+
+	protocol := TextProtocolIPv4
+	if addressFamily == BinaryAFInet6 {
+		protocol = TextProtocolIPv6
+	}
+
+	return []string{
+		protocol,
+		formatTextIP(srcAddr.IP, addressFamily),
+		formatTextIP(dstAddr.IP, addressFamily),
+		strconv.Itoa(srcAddr.Port),
+		strconv.Itoa(dstAddr.Port),
+	}, nil
+}
+
+// ipAddressFamily return header address family, when it set, or detect family
+// from source IP. Both IPs must be representable in this family.
+func (header *Header) ipAddressFamily(srcIP, dstIP net.IP) (byte, error) {
+	if srcIP.To16() == nil || dstIP.To16() == nil {
+		return 0, ErrInvalidIP
+	}
+
+	addressFamily := header.AddressFamily
+	if addressFamily == BinaryAFUnspec {
+		addressFamily = BinaryAFInet6
+		if srcIP.To4() != nil {
+			addressFamily = BinaryAFInet
+		}
+	}
+
+	switch addressFamily {
+	case BinaryAFInet:
+		if srcIP.To4() == nil || dstIP.To4() == nil {
+			return 0, ErrInvalidAddressFamily
+		}
+	case BinaryAFInet6:
+		if (srcIP.To4() == nil) != (dstIP.To4() == nil) && header.AddressFamily == BinaryAFUnspec {
+			return 0, ErrInvalidAddressFamily
+		}
+	default:
+		return 0, ErrInvalidAddressFamily
+	}
+
+	return addressFamily, nil
+}
+
+// formatTextIP format IPv4-mapped addresses of IPv6 family in IPv6 notation
+func formatTextIP(ip net.IP, addressFamily byte) string {
+	ip4 := ip.To4()
+	if addressFamily == BinaryAFInet6 && ip4 != nil {
+		return textIPv4MappedIPv6Prefix + ip4.String()
+	}
+	return ip.String()
+}
+
+func validPort(port int) bool {
+	return port >= 0 && port < 1<<uint(textPortBitSize)
+}
@@ -0,0 +1,150 @@
+package proxyprotocol_test
+
+import (
+	"bytes"
+	"net"
+	"reflect"
+	"testing"
+
+	"github.com/c0va23/go-proxyprotocol"
+)
+
+func TestHeader_FormatV1(t *testing.T) {
+	t.Run("header without addresses", func(t *testing.T) {
+		header := proxyprotocol.Header{}
+		testFormatV1(t, &header, "PROXY UNKNOWN\r\n", nil)
+	})
+
+	t.Run("LOCAL command", func(t *testing.T) {
+		header := proxyprotocol.Header{
+			SrcAddr: testIPv4SrcAddr,
+			DstAddr: testIPv4DstAddr,
+			Command: proxyprotocol.CommandLocal,
+		}
+		testFormatV1(t, &header, "PROXY UNKNOWN\r\n", nil)
+	})
+
+	t.Run("TCP over IPv4", func(t *testing.T) {
+		header := proxyprotocol.Header{
+			SrcAddr: testIPv4SrcAddr,
+			DstAddr: testIPv4DstAddr,
+		}
+		testFormatV1(t, &header, "PROXY TCP4 192.168.1.2 10.0.0.2 12345 8080\r\n", nil)
+	})
+
+	t.Run("TCP over IPv6", func(t *testing.T) {
+		header := proxyprotocol.Header{
+			SrcAddr: &net.TCPAddr{IP: net.ParseIP("2001:db8::1"), Port: 12345},
+			DstAddr: &net.TCPAddr{IP: net.ParseIP("2001:db8::2"), Port: 443},
+		}
+		testFormatV1(t, &header, "PROXY TCP6 2001:db8::1 2001:db8::2 12345 443\r\n", nil)
+	})
+
+	t.Run("IPv4 addresses with IPv6 family", func(t *testing.T) {
+		header := proxyprotocol.Header{
+			SrcAddr:       testIPv4SrcAddr,
+			DstAddr:       testIPv4DstAddr,
+			AddressFamily: proxyprotocol.BinaryAFInet6,
+		}
+		testFormatV1(t, &header, "PROXY TCP6 ::ffff:192.168.1.2 ::ffff:10.0.0.2 12345 8080\r\n", nil)
+	})
+
+	t.Run("maximal length", func(t *testing.T) {
+		ip := net.ParseIP("ffff:ffff:ffff:ffff:ffff:ffff:ffff:ffff")
+		header := proxyprotocol.Header{
+			SrcAddr: &net.TCPAddr{IP: ip, Port: 65535},
+			DstAddr: &net.TCPAddr{IP: ip, Port: 65535},
+		}
+		headerBuf, err := header.FormatV1()
+		if err != nil {
+			t.Fatalf("Unexpected error %s", err)
+		}
+		if len(headerBuf) > proxyprotocol.TextHeaderMaxLen {
+			t.Errorf("Unexpected header length %d", len(headerBuf))
+		}
+	})
+
+	t.Run("mixed address families", func(t *testing.T) {
+		header := proxyprotocol.Header{
+			SrcAddr: testIPv4SrcAddr,
+			DstAddr: &net.TCPAddr{IP: net.ParseIP("2001:db8::2"), Port: 443},
+		}
+		testFormatV1(t, &header, "", proxyprotocol.ErrInvalidAddressFamily)
+	})
+
+	t.Run("IPv6 addresses with IPv4 family", func(t *testing.T) {
+		header := proxyprotocol.Header{
+			SrcAddr:       &net.TCPAddr{IP: net.ParseIP("2001:db8::1"), Port: 12345},
+			DstAddr:       &net.TCPAddr{IP: net.ParseIP("2001:db8::2"), Port: 443},
+			AddressFamily: proxyprotocol.BinaryAFInet,
+		}
+		testFormatV1(t, &header, "", proxyprotocol.ErrInvalidAddressFamily)
+	})
+
+	t.Run("UDP addresses", func(t *testing.T) {
+		header := proxyprotocol.Header{
+			SrcAddr: &net.UDPAddr{IP: net.IPv4(192, 168, 1, 2), Port: 12345},
+			DstAddr: &net.UDPAddr{IP: net.IPv4(10, 0, 0, 2), Port: 53},
+		}
+		testFormatV1(t, &header, "", proxyprotocol.ErrUnsupportedAddress)
+	})
+
+	t.Run("invalid port", func(t *testing.T) {
+		header := proxyprotocol.Header{
+			SrcAddr: &net.TCPAddr{IP: net.IPv4(192, 168, 1, 2), Port: 65536},
+			DstAddr: testIPv4DstAddr,
+		}
+		testFormatV1(t, &header, "", proxyprotocol.ErrInvalidPort)
+	})
+
+	t.Run("invalid IP", func(t *testing.T) {
+		header := proxyprotocol.Header{
+			SrcAddr: &net.TCPAddr{Port: 12345},
+			DstAddr: testIPv4DstAddr,
+		}
+		testFormatV1(t, &header, "", proxyprotocol.ErrInvalidIP)
+	})
+}
+
+func testFormatV1(t *testing.T, header *proxyprotocol.Header, expectedHeaderLine string, expectedErr error) {
+	headerBuf, err := header.FormatV1()
+	if err != expectedErr {
+		t.Errorf("Unexpected error %v", err)
+	}
+
+	if string(headerBuf) != expectedHeaderLine {
+		t.Errorf("Unexpected header %q", headerBuf)
+	}
+}
+
+func TestHeader_WriteV1(t *testing.T) {
+	header := proxyprotocol.Header{
+		SrcAddr: testIPv4SrcAddr,
+		DstAddr: testIPv4DstAddr,
+	}
+
+	var buf bytes.Buffer
+	if err := header.WriteV1(&buf); err != nil {
+		t.Fatalf("Unexpected error %s", err)
+	}
+
+	textHeaderParser := proxyprotocol.NewTextHeaderParser(proxyprotocol.LoggerFunc(t.Logf))
+	parsedHeader, err := textHeaderParser.Parse(newTestReader(buf.Bytes()))
+	if err != nil {
+		t.Fatalf("Unexpected error %s", err)
+	}
+
+	if !reflect.DeepEqual(parsedHeader.SrcAddr.String(), header.SrcAddr.String()) ||
+		!reflect.DeepEqual(parsedHeader.DstAddr.String(), header.DstAddr.String()) {
+		t.Errorf("Unexpected parsed header %+v", parsedHeader)
+	}
+
+	headerBuf, err := parsedHeader.FormatV1()
+	if err != nil {
+		t.Fatalf("Unexpected error %s", err)
+	}
+
+	if !bytes.Equal(headerBuf, parsedHeader.Raw) {
+		t.Errorf("Unexpected header %q", headerBuf)
+	}
+}
@@ -0,0 +1,139 @@
+package proxyprotocol
+
+import (
+	"encoding/binary"
+	"errors"
+	"unicode/utf8"
+)
+
+// TLV is Type-Length-Value vector from proxyprotocol v2 header
+type TLV struct {
+	Type  byte
+	Value []byte
+}
+
+// TLV meta length
+const (
+	tlvTypeLen   = 1
+	tlvLengthLen = 2
+	tlvMetaLen   = tlvTypeLen + tlvLengthLen
+)
+
+// TLV errors
+var (
+	ErrInvalidTLVLen   = errors.New("invalid TLV length")
+	ErrTLVNotFound     = errors.New("TLV not found")
+	ErrInvalidTLVValue = errors.New("invalid TLV value")
+)
+
+// FindTLV return first TLV with type tlvType.
+// If header not contain TLV with this type, then return false.
+func (header *Header) FindTLV(tlvType byte) (TLV, bool) {
+	for _, tlv := range header.TLVs {
+		if tlv.Type == tlvType {
+			return tlv, true
+		}
+	}
+	return TLV{}, false
+}
+
+// FilterTLVs return all TLVs with type tlvType in received order
+func (header *Header) FilterTLVs(tlvType byte) []TLV {
+	var tlvs []TLV
+	for _, tlv := range header.TLVs {
+		if tlv.Type == tlvType {
+			tlvs = append(tlvs, tlv)
+		}
+	}
+	return tlvs
+}
+
+// ALPN return application protocol from PP2_TYPE_ALPN TLV.
+// Value must be not empty.
+func (header *Header) ALPN() ([]byte, error) {
+	tlv, found := header.FindTLV(TLVTypeALPN)
+	if !found {
+		return nil, ErrTLVNotFound
+	}
+
+	if len(tlv.Value) == 0 {
+		return nil, ErrInvalidTLVValue
+	}
+
+	return tlv.Value, nil
+}
+
+// Authority return host name (SNI) from PP2_TYPE_AUTHORITY TLV.
+// Value must be not empty UTF-8 string.
+func (header *Header) Authority() (string, error) {
+	tlv, found := header.FindTLV(TLVTypeAuthority)
+	if !found {
+		return "", ErrTLVNotFound
+	}
+
+	if len(tlv.Value) == 0 || !utf8.Valid(tlv.Value) {
+		return "", ErrInvalidTLVValue
+	}
+
+	return string(tlv.Value), nil
+}
+
+// UniqueID return connection ID from PP2_TYPE_UNIQUE_ID TLV.
+// Value must be not longer than TLVUniqueIDMaxLen bytes.
+func (header *Header) UniqueID() ([]byte, error) {
+	tlv, found := header.FindTLV(TLVTypeUniqueID)
+	if !found {
+		return nil, ErrTLVNotFound
+	}
+
+	if len(tlv.Value) > TLVUniqueIDMaxLen {
+		return nil, ErrInvalidTLVValue
+	}
+
+	return tlv.Value, nil
+}
+
+// NetNS return network namespace name from PP2_TYPE_NETNS TLV.
+// Value must be not empty US-ASCII string.
+func (header *Header) NetNS() (string, error) {
+	tlv, found := header.FindTLV(TLVTypeNetNS)
+	if !found {
+		return "", ErrTLVNotFound
+	}
+
+	if len(tlv.Value) == 0 {
+		return "", ErrInvalidTLVValue
+	}
+
+	for _, char := range tlv.Value {
+		if char >= utf8.RuneSelf {
+			return "", ErrInvalidTLVValue
+		}
+	}
+
+	return string(tlv.Value), nil
+}
+
+func parseTLVs(tlvBuf []byte) ([]TLV, error) {
+	var tlvs []TLV
+	for len(tlvBuf) > 0 {
+		if len(tlvBuf) < tlvMetaLen {
+			return nil, ErrInvalidTLVLen
+		}
+
+		tlvType := tlvBuf[0]
+		valueLen := int(binary.BigEndian.Uint16(tlvBuf[tlvTypeLen:tlvMetaLen]))
+		tlvBuf = tlvBuf[tlvMetaLen:]
+
+		if len(tlvBuf) < valueLen {
+			return nil, ErrInvalidTLVLen
+		}
+
+		tlvs = append(tlvs, TLV{
+			Type:  tlvType,
+			Value: tlvBuf[:valueLen:valueLen],
+		})
+		tlvBuf = tlvBuf[valueLen:]
+	}
+	return tlvs, nil
+}
@@ -0,0 +1,88 @@
+package proxyprotocol
+
+import (
+	"sync"
+)
+
+// TLVDecoder decode value of application-defined TLV
+type TLVDecoder interface {
+	Decode(value []byte) (interface{}, error)
+}
+
+// TLVDecoderFunc wrap decode func into TLVDecoder
+type TLVDecoderFunc func(value []byte) (interface{}, error)
+
+// Decode call inner decode func
+func (decoderFunc TLVDecoderFunc) Decode(value []byte) (interface{}, error) {
+	return decoderFunc(value)
+}
+
+// TLVDecoderRegistry map TLV types to TLVDecoder's.
+//
+// Registry used by BinaryHeaderParser to decode values of application-defined
+// TLVs (usually from custom range 0xE0-0xEF) while header parsing.
+type TLVDecoderRegistry struct {
+	mutex    sync.RWMutex
+	decoders map[byte]TLVDecoder
+}
+
+// NewTLVDecoderRegistry construct empty TLVDecoderRegistry
+func NewTLVDecoderRegistry() *TLVDecoderRegistry {
+	return &TLVDecoderRegistry{
+		decoders: make(map[byte]TLVDecoder),
+	}
+}
+
+// Register set decoder for TLV type. Previous decoder for this type replaced.
+func (registry *TLVDecoderRegistry) Register(tlvType byte, decoder TLVDecoder) {
+	registry.mutex.Lock()
+	defer registry.mutex.Unlock()
+
+	registry.decoders[tlvType] = decoder
+}
+
+// Decoder return decoder for TLV type
+func (registry *TLVDecoderRegistry) Decoder(tlvType byte) (TLVDecoder, bool) {
+	registry.mutex.RLock()
+	defer registry.mutex.RUnlock()
+
+	decoder, found := registry.decoders[tlvType]
+	return decoder, found
+}
+
+// decode first TLV of each registered type. Nil registry decode nothing.
+func (registry *TLVDecoderRegistry) decode(tlvs []TLV) (map[byte]interface{}, error) {
+	if registry == nil {
+		return nil, nil
+	}
+
+	var decoded map[byte]interface{}
+	for _, tlv := range tlvs {
+		if _, found := decoded[tlv.Type]; found {
+			continue
+		}
+
+		decoder, found := registry.Decoder(tlv.Type)
+		if !found {
+			continue
+		}
+
+		value, err := decoder.Decode(tlv.Value)
+		if err != nil {
+			return nil, err
+		}
+
+		if decoded == nil {
+			decoded = make(map[byte]interface{})
+		}
+		decoded[tlv.Type] = value
+	}
+
+	return decoded, nil
+}
+
+// DecodedTLV return value of TLV decoded by TLVDecoderRegistry
+func (header *Header) DecodedTLV(tlvType byte) (interface{}, bool) {
+	value, found := header.Decoded[tlvType]
+	return value, found
+}
@@ -0,0 +1,111 @@
+package proxyprotocol_test
+
+import (
+	"encoding/binary"
+	"errors"
+	"testing"
+
+	"github.com/c0va23/go-proxyprotocol"
+)
+
+func TestTLVDecoderRegistry_Decoder(t *testing.T) {
+	registry := proxyprotocol.NewTLVDecoderRegistry()
+
+	if _, found := registry.Decoder(0xE1); found {
+		t.Errorf("Unexpected decoder")
+	}
+
+	registry.Register(0xE1, proxyprotocol.TLVDecoderFunc(func(value []byte) (interface{}, error) {
+		return string(value), nil
+	}))
+
+	decoder, found := registry.Decoder(0xE1)
+	if !found {
+		t.Fatal("Decoder not found")
+	}
+
+	value, err := decoder.Decode([]byte("tenant"))
+	if err != nil {
+		t.Errorf("Unexpected error %s", err)
+	}
+
+	if value != "tenant" {
+		t.Errorf("Unexpected value %v", value)
+	}
+}
+
+func TestBinaryHeaderParser_WithTLVDecoderRegistry(t *testing.T) {
+	logger := proxyprotocol.LoggerFunc(t.Logf)
+	commandVersion := proxyprotocol.BinaryCommandProxy | proxyprotocol.BinaryVersion2
+
+	decodeErr := errors.New("decode error")
+	registry := proxyprotocol.NewTLVDecoderRegistry()
+	registry.Register(0xE1, proxyprotocol.TLVDecoderFunc(func(value []byte) (interface{}, error) {
+		if len(value) == 0 {
+			return nil, decodeErr
+		}
+		return string(value), nil
+	}))
+
+	binaryHeaderParser := proxyprotocol.NewBinaryHeaderParser(logger).WithTLVDecoderRegistry(registry)
+
+	t.Run("when registered TLV received", func(t *testing.T) {
+		tlvData := append(buildTLV(0xE2, []byte("other")), buildTLV(0xE1, []byte("first"))...)
+		tlvData = append(tlvData, buildTLV(0xE1, []byte("second"))...)
+		data := buildBinaryHeader(commandVersion, proxyprotocol.BinaryProtocolTCPoverIPv4, testIPv4AddressData, tlvData)
+
+		header, err := binaryHeaderParser.Parse(newTestReader(data))
+		if err != nil {
+			t.Fatalf("Unexpected error %s", err)
+		}
+
+		if value, found := header.DecodedTLV(0xE1); !found || value != "first" {
+			t.Errorf("Unexpected decoded value %v", value)
+		}
+
+		if value, found := header.DecodedTLV(0xE2); found {
+			t.Errorf("Unexpected decoded value %v", value)
+		}
+	})
+
+	t.Run("when decoder return error", func(t *testing.T) {
+		tlvData := buildTLV(0xE1, []byte{})
+		testParser(t, testParserArgs{
+			headerParser: binaryHeaderParser,
+			data:         buildBinaryHeader(commandVersion, proxyprotocol.BinaryProtocolTCPoverIPv4, testIPv4AddressData, tlvData),
+			err:          decodeErr,
+			readAll:      true,
+		})
+	})
+
+	t.Run("when decoder return error and checksum mismatch", func(t *testing.T) {
+		tlvData := append(
+			buildTLV(0xE1, []byte{}),
+			buildTLV(proxyprotocol.TLVTypeCRC32C, make([]byte, proxyprotocol.TLVCRC32CLen))...,
+		)
+		data := buildBinaryHeader(commandVersion, proxyprotocol.BinaryProtocolTCPoverIPv4, testIPv4AddressData, tlvData)
+		binary.BigEndian.PutUint32(data[len(data)-proxyprotocol.TLVCRC32CLen:], 1)
+
+		if _, err := binaryHeaderParser.Parse(newTestReader(data)); err != proxyprotocol.ErrChecksumMismatch {
+			t.Errorf("Unexpected error %v", err)
+		}
+	})
+
+	t.Run("when registered TLV not received", func(t *testing.T) {
+		data := buildBinaryHeader(commandVersion, proxyprotocol.BinaryProtocolTCPoverIPv4, testIPv4AddressData, nil)
+		testParser(t, testParserArgs{
+			headerParser: binaryHeaderParser,
+			data:         data,
+			header: &proxyprotocol.Header{
+				SrcAddr:       testIPv4SrcAddr,
+				DstAddr:       testIPv4DstAddr,
+				Version:       proxyprotocol.Version2,
+				Command:       proxyprotocol.CommandProxy,
+				AddressFamily: proxyprotocol.BinaryAFInet,
+				Transport:     proxyprotocol.BinaryTPStream,
+				Raw:           data,
+			},
+			readAll: true,
+		})
+	})
+}
@@ -0,0 +1,191 @@
+package proxyprotocol_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/c0va23/go-proxyprotocol"
+)
+
+func TestHeader_FindTLV(t *testing.T) {
+	header := proxyprotocol.Header{
+		TLVs: []proxyprotocol.TLV{
+			{Type: 0xE0, Value: []byte("first")},
+			{Type: 0xE1, Value: []byte("other")},
+			{Type: 0xE0, Value: []byte("second")},
+		},
+	}
+
+	t.Run("when TLV exists", func(t *testing.T) {
+		tlv, found := header.FindTLV(0xE0)
+		if !found {
+			t.Fatal("TLV not found")
+		}
+
+		expectedTLV := proxyprotocol.TLV{Type: 0xE0, Value: []byte("first")}
+		if !reflect.DeepEqual(expectedTLV, tlv) {
+			t.Errorf("Unexpected TLV %v", tlv)
+		}
+	})
+
+	t.Run("when TLV not exists", func(t *testing.T) {
+		tlv, found := header.FindTLV(0xE2)
+		if found {
+			t.Errorf("Unexpected TLV %v", tlv)
+		}
+	})
+}
+
+func TestHeader_FilterTLVs(t *testing.T) {
+	header := proxyprotocol.Header{
+		TLVs: []proxyprotocol.TLV{
+			{Type: 0xE0, Value: []byte("first")},
+			{Type: 0xE1, Value: []byte("other")},
+			{Type: 0xE0, Value: []byte("second")},
+		},
+	}
+
+	t.Run("when TLVs exists", func(t *testing.T) {
+		tlvs := header.FilterTLVs(0xE0)
+
+		expectedTLVs := []proxyprotocol.TLV{
+			{Type: 0xE0, Value: []byte("first")},
+			{Type: 0xE0, Value: []byte("second")},
+		}
+		if !reflect.DeepEqual(expectedTLVs, tlvs) {
+			t.Errorf("Unexpected TLVs %v", tlvs)
+		}
+	})
+
+	t.Run("when TLVs not exists", func(t *testing.T) {
+		if tlvs := header.FilterTLVs(0xE2); tlvs != nil {
+			t.Errorf("Unexpected TLVs %v", tlvs)
+		}
+	})
+}
+
+func TestHeader_ALPN(t *testing.T) {
+	t.Run("when TLV not exists", func(t *testing.T) {
+		header := proxyprotocol.Header{}
+		if _, err := header.ALPN(); err != proxyprotocol.ErrTLVNotFound {
+			t.Errorf("Unexpected error %v", err)
+		}
+	})
+
+	t.Run("when TLV empty", func(t *testing.T) {
+		header := proxyprotocol.Header{
+			TLVs: []proxyprotocol.TLV{{Type: proxyprotocol.TLVTypeALPN, Value: []byte{}}},
+		}
+		if _, err := header.ALPN(); err != proxyprotocol.ErrInvalidTLVValue {
+			t.Errorf("Unexpected error %v", err)
+		}
+	})
+
+	t.Run("when TLV valid", func(t *testing.T) {
+		header := proxyprotocol.Header{
+			TLVs: []proxyprotocol.TLV{{Type: proxyprotocol.TLVTypeALPN, Value: []byte("h2")}},
+		}
+		alpn, err := header.ALPN()
+		if err != nil {
+			t.Errorf("Unexpected error %s", err)
+		}
+		if string(alpn) != "h2" {
+			t.Errorf("Unexpected ALPN %q", alpn)
+		}
+	})
+}
+
+func TestHeader_Authority(t *testing.T) {
+	t.Run("when TLV not exists", func(t *testing.T) {
+		header := proxyprotocol.Header{}
+		if _, err := header.Authority(); err != proxyprotocol.ErrTLVNotFound {
+			t.Errorf("Unexpected error %v", err)
+		}
+	})
+
+	t.Run("when TLV not UTF-8", func(t *testing.T) {
+		header := proxyprotocol.Header{
+			TLVs: []proxyprotocol.TLV{{Type: proxyprotocol.TLVTypeAuthority, Value: []byte{0xFF, 0xFE}}},
+		}
+		if _, err := header.Authority(); err != proxyprotocol.ErrInvalidTLVValue {
+			t.Errorf("Unexpected error %v", err)
+		}
+	})
+
+	t.Run("when TLV valid", func(t *testing.T) {
+		header := proxyprotocol.Header{
+			TLVs: []proxyprotocol.TLV{{Type: proxyprotocol.TLVTypeAuthority, Value: []byte("example.com")}},
+		}
+		authority, err := header.Authority()
+		if err != nil {
+			t.Errorf("Unexpected error %s", err)
+		}
+		if authority != "example.com" {
+			t.Errorf("Unexpected authority %q", authority)
+		}
+	})
+}
+
+func TestHeader_UniqueID(t *testing.T) {
+	t.Run("when TLV not exists", func(t *testing.T) {
+		header := proxyprotocol.Header{}
+		if _, err := header.UniqueID(); err != proxyprotocol.ErrTLVNotFound {
+			t.Errorf("Unexpected error %v", err)
+		}
+	})
+
+	t.Run("when TLV too long", func(t *testing.T) {
+		value := make([]byte, proxyprotocol.TLVUniqueIDMaxLen+1)
+		header := proxyprotocol.Header{
+			TLVs: []proxyprotocol.TLV{{Type: proxyprotocol.TLVTypeUniqueID, Value: value}},
+		}
+		if _, err := header.UniqueID(); err != proxyprotocol.ErrInvalidTLVValue {
+			t.Errorf("Unexpected error %v", err)
+		}
+	})
+
+	t.Run("when TLV has max length", func(t *testing.T) {
+		value := make([]byte, proxyprotocol.TLVUniqueIDMaxLen)
+		header := proxyprotocol.Header{
+			TLVs: []proxyprotocol.TLV{{Type: proxyprotocol.TLVTypeUniqueID, Value: value}},
+		}
+		uniqueID, err := header.UniqueID()
+		if err != nil {
+			t.Errorf("Unexpected error %s", err)
+		}
+		if !reflect.DeepEqual(value, uniqueID) {
+			t.Errorf("Unexpected unique ID %v", uniqueID)
+		}
+	})
+}
+
+func TestHeader_NetNS(t *testing.T) {
+	t.Run("when TLV not exists", func(t *testing.T) {
+		header := proxyprotocol.Header{}
+		if _, err := header.NetNS(); err != proxyprotocol.ErrTLVNotFound {
+			t.Errorf("Unexpected error %v", err)
+		}
+	})
+
+	t.Run("when TLV not ASCII", func(t *testing.T) {
+		header := proxyprotocol.Header{
+			TLVs: []proxyprotocol.TLV{{Type: proxyprotocol.TLVTypeNetNS, Value: []byte("ns\xC3\xA9")}},
+		}
+		if _, err := header.NetNS(); err != proxyprotocol.ErrInvalidTLVValue {
+			t.Errorf("Unexpected error %v", err)
+		}
+	})
+
+	t.Run("when TLV valid", func(t *testing.T) {
+		header := proxyprotocol.Header{
+			TLVs: []proxyprotocol.TLV{{Type: proxyprotocol.TLVTypeNetNS, Value: []byte("blue")}},
+		}
+		netNS, err := header.NetNS()
+		if err != nil {
+			t.Errorf("Unexpected error %s", err)
+		}
+		if netNS != "blue" {
+			t.Errorf("Unexpected namespace %q", netNS)
+		}
+	})
+}
@@ -0,0 +1,137 @@
+package proxyprotocol
+
+import (
+	"errors"
+	"net"
+	"strings"
+)
+
+// ErrTranslationLoss returned when header translation lose information not
+// allowed by translator policy
+var ErrTranslationLoss = errors.New("translation lose information")
+
+// TranslationLoss is set of header information which have not form in target
+// header version
+type TranslationLoss uint
+
+// Translation losses
+const (
+	// LossTLVs mean that TLVs (except NOOP padding) dropped
+	LossTLVs TranslationLoss = 1 << iota
+	// LossUnixAddress mean that UNIX addresses encoded as UNKNOWN protocol
+	LossUnixAddress
+	// LossDatagram mean that UDP addresses encoded as TCP addresses
+	LossDatagram
+	// LossLocalCommand mean that LOCAL command encoded as UNKNOWN protocol
+	LossLocalCommand
+)
+
+var translationLossNames = []struct {
+	loss TranslationLoss
+	name string
+}{
+	{LossTLVs, "TLVs"},
+	{LossUnixAddress, "UNIX address"},
+	{LossDatagram, "datagram transport"},
+	{LossLocalCommand, "LOCAL command"},
+}
+
+// String return comma separated names of lost information
+func (loss TranslationLoss) String() string {
+	var names []string
+	for _, lossName := range translationLossNames {
+		if loss&lossName.loss != 0 {
+			names = append(names, lossName.name)
+		}
+	}
+	return strings.Join(names, ", ")
+}
+
+// Translator re-encode parsed header into target version.
+//
+// Version1 can not represent TLVs, UNIX addresses, UDP transport and LOCAL
+// command. Translation which lose information not included into AllowedLoss
+// refused with ErrTranslationLoss. Version2 can represent any Version1 header.
+type Translator struct {
+	// Version is target header version
+	Version     byte
+	AllowedLoss TranslationLoss
+	// BinaryFormatOptions used to encode Version2 headers
+	BinaryFormatOptions BinaryFormatOptions
+}
+
+// NewTranslator construct Translator into target version which not allow any
+// loss of information
+func NewTranslator(version byte) Translator {
+	return Translator{
+		Version: version,
+	}
+}
+
+// WithAllowedLoss copy Translator and set AllowedLoss
+func (translator Translator) WithAllowedLoss(allowedLoss TranslationLoss) Translator {
+	newTranslator := translator
+	newTranslator.AllowedLoss = allowedLoss
+	return newTranslator
+}
+
+// Translate encode header into target version. Lost information returned
+// even when translation refused.
+func (translator Translator) Translate(header *Header) ([]byte, TranslationLoss, error) {
+	switch translator.Version {
+	case Version1:
+		v1Header, loss := translateV1(header)
+		if loss&^translator.AllowedLoss != 0 {
+			return nil, loss, ErrTranslationLoss
+		}
+
+		headerBuf, err := v1Header.FormatV1()
+		return headerBuf, loss, err
+	case Version2:
+		headerBuf, err := header.FormatV2WithOptions(translator.BinaryFormatOptions)
+		return headerBuf, 0, err
+	default:
+		return nil, 0, ErrUnknownVersion
+	}
+}
+
+// translateV1 return copy of header representable in Version1 and lost
+// information
+func translateV1(header *Header) (*Header, TranslationLoss) {
+	var loss TranslationLoss
+
+	for _, tlv := range header.TLVs {
+		if tlv.Type != TLVTypeNoop {
+			loss |= LossTLVs
+			break
+		}
+	}
+
+	v1Header := &Header{
+		SrcAddr:       header.SrcAddr,
+		DstAddr:       header.DstAddr,
+		Version:       Version1,
+		Command:       header.Command,
+		AddressFamily: header.AddressFamily,
+	}
+
+	if header.Command == CommandLocal {
+		loss |= LossLocalCommand
+		return v1Header, loss
+	}
+
+	switch srcAddr := header.SrcAddr.(type) {
+	case *net.UnixAddr:
+		loss |= LossUnixAddress
+		v1Header.SrcAddr, v1Header.DstAddr = nil, nil
+		v1Header.AddressFamily = BinaryAFUnspec
+	case *net.UDPAddr:
+		if dstAddr, ok := header.DstAddr.(*net.UDPAddr); ok {
+			loss |= LossDatagram
+			v1Header.SrcAddr = &net.TCPAddr{IP: srcAddr.IP, Port: srcAddr.Port, Zone: srcAddr.Zone}
+			v1Header.DstAddr = &net.TCPAddr{IP: dstAddr.IP, Port: dstAddr.Port, Zone: dstAddr.Zone}
+		}
+	}
+
+	return v1Header, loss
+}
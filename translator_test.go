@@ -0,0 +1,144 @@
+package proxyprotocol_test
+
+import (
+	"bufio"
+	"bytes"
+	"net"
+	"testing"
+
+	"github.com/c0va23/go-proxyprotocol"
+)
+
+func TestTranslationLoss_String(t *testing.T) {
+	loss := proxyprotocol.LossTLVs | proxyprotocol.LossDatagram
+	if name := loss.String(); name != "TLVs, datagram transport" {
+		t.Errorf("Unexpected name %s", name)
+	}
+}
+
+func TestTranslator_Translate(t *testing.T) {
+	logger := proxyprotocol.LoggerFunc(t.Logf)
+
+	t.Run("from v1 to v2", func(t *testing.T) {
+		header := &proxyprotocol.Header{
+			SrcAddr:       testIPv4SrcAddr,
+			DstAddr:       testIPv4DstAddr,
+			Version:       proxyprotocol.Version1,
+			AddressFamily: proxyprotocol.BinaryAFInet,
+			Transport:     proxyprotocol.BinaryTPStream,
+		}
+
+		headerBuf, loss, err := proxyprotocol.NewTranslator(proxyprotocol.Version2).Translate(header)
+		if err != nil || loss != 0 {
+			t.Fatalf("Unexpected loss %s, error %v", loss, err)
+		}
+
+		parser := proxyprotocol.NewBinaryHeaderParser(logger)
+		v2Header, err := parser.Parse(bufio.NewReader(bytes.NewReader(headerBuf)))
+		if err != nil {
+			t.Fatalf("Unexpected error %s", err)
+		}
+		if v2Header.SrcAddr.String() != testIPv4SrcAddr.String() ||
+			v2Header.DstAddr.String() != testIPv4DstAddr.String() {
+			t.Errorf("Unexpected header %+v", v2Header)
+		}
+	})
+
+	t.Run("from v2 to v1 without loss", func(t *testing.T) {
+		header := &proxyprotocol.Header{
+			SrcAddr: testIPv4SrcAddr,
+			DstAddr: testIPv4DstAddr,
+			Version: proxyprotocol.Version2,
+			TLVs:    []proxyprotocol.TLV{{Type: proxyprotocol.TLVTypeNoop}},
+		}
+
+		headerBuf, loss, err := proxyprotocol.NewTranslator(proxyprotocol.Version1).Translate(header)
+		if err != nil || loss != 0 {
+			t.Fatalf("Unexpected loss %s, error %v", loss, err)
+		}
+
+		expectedBuf := "PROXY TCP4 192.168.1.2 10.0.0.2 12345 8080\r\n"
+		if string(headerBuf) != expectedBuf {
+			t.Errorf("Unexpected header %q", headerBuf)
+		}
+	})
+
+	type lossTestCase struct {
+		name        string
+		header      *proxyprotocol.Header
+		loss        proxyprotocol.TranslationLoss
+		expectedBuf string
+	}
+
+	for _, testCase := range []lossTestCase{
+		{
+			name: "TLVs",
+			header: &proxyprotocol.Header{
+				SrcAddr: testIPv4SrcAddr,
+				DstAddr: testIPv4DstAddr,
+				TLVs:    []proxyprotocol.TLV{{Type: proxyprotocol.TLVTypeAuthority, Value: []byte("example.com")}},
+			},
+			loss:        proxyprotocol.LossTLVs,
+			expectedBuf: "PROXY TCP4 192.168.1.2 10.0.0.2 12345 8080\r\n",
+		},
+		{
+			name: "UNIX address",
+			header: &proxyprotocol.Header{
+				SrcAddr: &net.UnixAddr{Name: "/src.sock", Net: "unix"},
+				DstAddr: &net.UnixAddr{Name: "/dst.sock", Net: "unix"},
+			},
+			loss:        proxyprotocol.LossUnixAddress,
+			expectedBuf: "PROXY UNKNOWN\r\n",
+		},
+		{
+			name: "datagram",
+			header: &proxyprotocol.Header{
+				SrcAddr: &net.UDPAddr{IP: net.ParseIP("::1"), Port: 1000},
+				DstAddr: &net.UDPAddr{IP: net.ParseIP("::2"), Port: 2000},
+			},
+			loss:        proxyprotocol.LossDatagram,
+			expectedBuf: "PROXY TCP6 ::1 ::2 1000 2000\r\n",
+		},
+		{
+			name: "LOCAL command",
+			header: &proxyprotocol.Header{
+				SrcAddr: testIPv4SrcAddr,
+				DstAddr: testIPv4DstAddr,
+				Command: proxyprotocol.CommandLocal,
+			},
+			loss:        proxyprotocol.LossLocalCommand,
+			expectedBuf: "PROXY UNKNOWN\r\n",
+		},
+	} {
+		t.Run(testCase.name, func(t *testing.T) {
+			translator := proxyprotocol.NewTranslator(proxyprotocol.Version1)
+
+			t.Run("when loss not allowed", func(t *testing.T) {
+				headerBuf, loss, err := translator.Translate(testCase.header)
+				if err != proxyprotocol.ErrTranslationLoss {
+					t.Errorf("Unexpected error %v", err)
+				}
+				if loss != testCase.loss || headerBuf != nil {
+					t.Errorf("Unexpected loss %s, header %q", loss, headerBuf)
+				}
+			})
+
+			t.Run("when loss allowed", func(t *testing.T) {
+				headerBuf, loss, err := translator.WithAllowedLoss(testCase.loss).Translate(testCase.header)
+				if err != nil {
+					t.Errorf("Unexpected error %v", err)
+				}
+				if loss != testCase.loss || string(headerBuf) != testCase.expectedBuf {
+					t.Errorf("Unexpected loss %s, header %q", loss, headerBuf)
+				}
+			})
+		})
+	}
+
+	t.Run("unknown version", func(t *testing.T) {
+		_, _, err := proxyprotocol.NewTranslator(3).Translate(&proxyprotocol.Header{})
+		if err != proxyprotocol.ErrUnknownVersion {
+			t.Errorf("Unexpected error %v", err)
+		}
+	})
+}